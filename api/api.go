@@ -4,8 +4,10 @@ import (
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/samber/do"
 	"legion-bot-v2/bot"
+	"legion-bot-v2/bot/achievements"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/rituals"
 	"legion-bot-v2/cheatdetect"
 	"legion-bot-v2/config"
 	"legion-bot-v2/db"
@@ -30,6 +32,8 @@ type Server struct {
 	cheatDetector *cheatdetect.Detector
 	steamClient   steam.Steam
 	killerMap     map[string]killer.Killer
+	achievements  achievements.Engine
+	rituals       rituals.Engine
 	stateCache    *ttlcache.Cache[string, struct{}]
 	mux           *http.ServeMux
 }
@@ -58,6 +62,8 @@ func NewServer(di *do.Injector) *Server {
 		cheatDetector: do.MustInvoke[*cheatdetect.Detector](di),
 		steamClient:   do.MustInvoke[steam.Steam](di),
 		killerMap:     do.MustInvoke[map[string]killer.Killer](di),
+		achievements:  do.MustInvoke[achievements.Engine](di),
+		rituals:       do.MustInvoke[rituals.Engine](di),
 		stateCache:    stateCache,
 		mux:           http.NewServeMux(),
 	}
@@ -71,7 +77,27 @@ func NewServer(di *do.Injector) *Server {
 
 	server.mux.HandleFunc("/api/stats/{channel}", server.handleChannelStats)
 	server.mux.HandleFunc("/api/stats/{channel}/{username}", server.handleUserStats)
+	server.mux.HandleFunc("/api/help/{channel}", server.handleHelp)
 	server.mux.HandleFunc("/api/summonKiller", server.handleSummonKiller)
+	server.mux.HandleFunc("/api/scheduler", server.handleSchedulerPreview)
+	server.mux.HandleFunc("/api/scheduler/queue", server.handleSchedulerQueue)
+	server.mux.HandleFunc("/api/session", server.handleSession)
+	server.mux.HandleFunc("/api/addons", server.handleAddOns)
+	server.mux.HandleFunc("/api/commands", server.handleCommands)
+	server.mux.HandleFunc("/api/commands/{name}", server.handleCommand)
+	server.mux.HandleFunc("/api/customKillers", server.handleCustomKillers)
+	server.mux.HandleFunc("/api/customKillers/{name}", server.handleCustomKiller)
+	server.mux.HandleFunc("/api/locale", server.handleLocale)
+	server.mux.HandleFunc("/api/locale/{key}", server.handleLocaleKey)
+
+	server.mux.HandleFunc("/api/bloodpoints/ledger", server.handleBloodpointsLedger)
+	server.mux.HandleFunc("/api/bloodpoints/refund", server.handleBloodpointsRefund)
+	server.mux.HandleFunc("/api/safeMode", server.handleSafeMode)
+	server.mux.HandleFunc("/api/punishments", server.handlePunishments)
+	server.mux.HandleFunc("/api/punishments/revert", server.handlePunishmentRevert)
+
+	server.mux.HandleFunc("/api/seasons/{channel}", server.handleSeasonList)
+	server.mux.HandleFunc("/api/seasons/{channel}/{id}", server.handleSeason)
 
 	server.mux.HandleFunc("/api/admin/users", server.handleUserList)
 	server.mux.HandleFunc("/api/admin/loginAs", server.handleLoginAs)
@@ -80,6 +106,7 @@ func NewServer(di *do.Injector) *Server {
 	server.mux.HandleFunc("/api/webhook/raids", server.handleOutgoingRaid)
 	server.mux.HandleFunc("/api/webhook/stream/start", server.handleStreamStart)
 	server.mux.HandleFunc("/api/webhook/stream/end", server.handleStreamEnd)
+	server.mux.HandleFunc("/api/webhook/channel/update", server.handleChannelUpdate)
 
 	server.mux.HandleFunc("/api/cheatDetect", server.handleCheatDetect)
 
@@ -1,6 +1,11 @@
 package dao
 
-import "time"
+import (
+	"legion-bot-v2/bot/achievements"
+	"legion-bot-v2/bot/rituals"
+	"legion-bot-v2/db"
+	"time"
+)
 
 type TwitchUser struct {
 	Login           string `json:"login"`
@@ -45,4 +50,98 @@ type ChannelStatusResponse struct {
 	Title         string        `json:"title"`
 	Subtitle      string        `json:"subtitle"`
 	TimeRemaining time.Duration `json:"timeRemaining"`
+	NextKiller    string        `json:"nextKiller"`
+	NextKillerETA time.Duration `json:"nextKillerEta"`
+	Budget        *BudgetStatus `json:"budget,omitempty"`
+	// Warnings explain why some enabled killers can't run
+	Warnings []string `json:"warnings"`
+}
+
+// BudgetStatus is the moderation budget spent during the current stream, the limits are zero when disabled
+type BudgetStatus struct {
+	Spent             time.Duration `json:"spent"`
+	StreamTimeout     time.Duration `json:"streamTimeout"`
+	Users             int           `json:"users"`
+	SessionUsers      int           `json:"sessionUsers"`
+	SessionUsersLimit int           `json:"sessionUsersLimit"`
+	Skipped           int           `json:"skipped"`
+}
+
+type RefundRequest struct {
+	ID uint64 `json:"id"`
+}
+
+type RevertRequest struct {
+	ID uint64 `json:"id"`
+}
+
+type PunishmentEntry struct {
+	db.Punishment
+	Outstanding bool `json:"outstanding"`
+}
+
+type UserStatsResponse struct {
+	Stats        map[string]int          `json:"stats"`
+	Achievements []achievements.Unlocked `json:"achievements"`
+	Rituals      []rituals.Progress      `json:"rituals"`
+	Titles       []string                `json:"titles"`
+}
+
+type SeasonSummary struct {
+	ID           uint64       `json:"id"`
+	Start        time.Time    `json:"start"`
+	End          time.Time    `json:"end"`
+	Participants int          `json:"participants"`
+	Winner       *db.Standing `json:"winner"`
+}
+
+type KillerQueueRequest struct {
+	Queue []string `json:"queue"`
+}
+
+type SessionInfo struct {
+	Killer       string               `json:"killer"`
+	Start        time.Time            `json:"start"`
+	Participants int                  `json:"participants"`
+	Pressure     float64              `json:"pressure"`
+	Modifiers    []db.SettingModifier `json:"modifiers"`
+	AddOns       []string             `json:"addOns"`
+	Clips        []string             `json:"clips"`
+	Effective    any                  `json:"effective"`
+}
+
+type SessionResponse struct {
+	Pressure float64      `json:"pressure"`
+	Current  *SessionInfo `json:"current"`
+	Last     *SessionInfo `json:"last"`
+}
+
+type AddOnInfo struct {
+	ID        string               `json:"id"`
+	Killer    string               `json:"killer"`
+	Rarity    string               `json:"rarity"`
+	Name      string               `json:"name"`
+	Banned    bool                 `json:"banned"`
+	Modifiers []db.SettingModifier `json:"modifiers"`
+}
+
+type CommandHelp struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases"`
+	Permission  string   `json:"permission"`
+	Description string   `json:"description"`
+}
+
+type KillerHelp struct {
+	Name        string        `json:"name"`
+	DisplayName string        `json:"displayName"`
+	Description string        `json:"description"`
+	Enabled     bool          `json:"enabled"`
+	Commands    []CommandHelp `json:"commands"`
+}
+
+type HelpResponse struct {
+	StatsURL string        `json:"statsUrl"`
+	Commands []CommandHelp `json:"commands"`
+	Killers  []KillerHelp  `json:"killers"`
 }
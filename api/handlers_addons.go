@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"legion-bot-v2/api/dao"
+	"net/http"
+	"slices"
+	"sort"
+)
+
+func (s *Server) handleAddOns(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chanState := s.database.GetState(claims.TwitchUser.Login)
+	lang := chanState.Settings.Language
+
+	var banned []string
+	if chanState.Settings.AddOns != nil {
+		banned = chanState.Settings.AddOns.Banned
+	}
+
+	killerNames := make([]string, 0, len(s.killerMap))
+	for name := range s.killerMap {
+		killerNames = append(killerNames, name)
+	}
+	sort.Strings(killerNames)
+
+	result := make([]dao.AddOnInfo, 0)
+	for _, name := range killerNames {
+		for _, addOn := range s.bot.AddOns(name) {
+			result = append(result, dao.AddOnInfo{
+				ID:        addOn.ID,
+				Killer:    addOn.Killer,
+				Rarity:    addOn.Rarity,
+				Name:      addOn.LocalName(lang),
+				Banned:    slices.Contains(banned, addOn.ID),
+				Modifiers: addOn.Modifiers,
+			})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -6,14 +6,19 @@ import (
 	"fmt"
 	"github.com/jellydator/ttlcache/v3"
 	"legion-bot-v2/api/dao"
+	"legion-bot-v2/bot/killer/custom"
 	"legion-bot-v2/db"
 	"legion-bot-v2/util"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 )
 
+const maxProtectedUsers = 100
+
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Login attempt")
 
@@ -186,6 +191,113 @@ func (s *Server) updateSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := time.LoadLocation(newSettings.Timezone); err != nil {
+		http.Error(w, "Invalid timezone", http.StatusBadRequest)
+		return
+	}
+
+	if !slices.Contains(s.localiser.Languages(), newSettings.Language) {
+		http.Error(w, "Invalid language", http.StatusBadRequest)
+		return
+	}
+
+	if newSettings.Rituals != nil && (newSettings.Rituals.ResetHour < 0 || newSettings.Rituals.ResetHour > 23) {
+		http.Error(w, "Invalid ritual reset hour", http.StatusBadRequest)
+		return
+	}
+
+	if g := newSettings.Greetings; g != nil && (g.ReturningAfter < 0 || g.Cooldown < 0 || len(g.Milestones) > 20 || slices.ContainsFunc(g.Milestones, func(m int) bool { return m <= 0 })) {
+		http.Error(w, "Invalid greetings settings", http.StatusBadRequest)
+		return
+	}
+
+	if b := newSettings.Budget; b != nil && (b.StreamTimeout < 0 || b.UserTimeout < 0 || b.SessionUsers < 0 || b.RepeatFactor < 0 || b.RepeatFactor > 1) {
+		http.Error(w, "Invalid budget settings", http.StatusBadRequest)
+		return
+	}
+
+	if p := newSettings.Protection; p != nil {
+		if len(p.Users) > maxProtectedUsers || p.MinSubTier < 0 || p.MinSubTier > 3 || p.FollowedFor < 0 {
+			http.Error(w, "Invalid protection settings", http.StatusBadRequest)
+			return
+		}
+
+		for i, username := range p.Users {
+			p.Users[i] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+		}
+	}
+
+	if c := newSettings.Clips; c != nil && c.AutoClipCooldown < 0 {
+		http.Error(w, "Invalid clips settings", http.StatusBadRequest)
+		return
+	}
+
+	if d := newSettings.Difficulty; d != nil && (d.MinPressure <= 0 || d.MaxPressure < d.MinPressure) {
+		http.Error(w, "Invalid difficulty pressure range", http.StatusBadRequest)
+		return
+	}
+
+	if newSettings.Killers.Scheduler != nil {
+		for _, window := range newSettings.Killers.Scheduler.Windows {
+			if _, err := util.ParseCron(window.Cron, newSettings.Location()); err != nil || window.Duration <= 0 {
+				http.Error(w, "Invalid killer window", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	if seasons := newSettings.Seasons; seasons != nil {
+		if _, err := util.ParseCron(seasons.Schedule, newSettings.Location()); err != nil || seasons.LeaderboardSize < 0 {
+			http.Error(w, "Invalid seasons settings", http.StatusBadRequest)
+			return
+		}
+	}
+
+	customKillers, err := custom.ValidateAll(newSettings.CustomKillers)
+	if err != nil {
+		http.Error(w, "Invalid custom killers: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	newSettings.CustomKillers = customKillers
+
+	if newSettings.Commands == nil {
+		newSettings.Commands = db.DefaultCommandsSettings()
+	}
+
+	if newSettings.Bloodpoints == nil {
+		newSettings.Bloodpoints = db.DefaultBloodpointsSettings()
+	}
+
+	if newSettings.Rituals == nil {
+		newSettings.Rituals = db.DefaultRitualsSettings()
+	}
+
+	if newSettings.Killers.Scheduler == nil {
+		newSettings.Killers.Scheduler = db.DefaultSchedulerSettings()
+	}
+
+	if newSettings.Difficulty == nil {
+		newSettings.Difficulty = db.DefaultDifficultySettings()
+	}
+
+	customCommands, err := s.bot.ValidateCustomCommands(newSettings.Commands.Custom)
+	if err != nil {
+		http.Error(w, "Invalid custom commands: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	newSettings.Commands.Custom = customCommands
+
+	if newSettings.LocaleOverrides == nil {
+		newSettings.LocaleOverrides = make(map[string]string)
+	}
+
+	for key, text := range newSettings.LocaleOverrides {
+		if err := s.localiser.ValidateOverride(key, text); err != nil {
+			http.Error(w, "Invalid override for "+key+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	s.database.UpdateState(claims.TwitchUser.Login, func(state *db.ChannelState) {
 		state.Settings = newSettings
 	})
@@ -194,8 +306,11 @@ func (s *Server) updateSettings(w http.ResponseWriter, r *http.Request) {
 		s.chatProducer.RemoveChannel(claims.TwitchUser.Login)
 	} else if oldSettings.Disabled && !newSettings.Disabled {
 		s.chatProducer.AddChannel(claims.TwitchUser.Login)
+		go s.bot.Refresh(claims.TwitchUser.Login)
 	}
 
+	s.bot.SyncCustomCommands(claims.TwitchUser.Login)
+
 	if oldSettings.Steam.PinnedCommentText != newSettings.Steam.PinnedCommentText {
 		s.steamClient.UpdatePinnedComment(claims.TwitchUser.Login)
 	}
@@ -367,5 +482,10 @@ func (s *Server) handleUserStats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user.Stats)
+	json.NewEncoder(w).Encode(dao.UserStatsResponse{
+		Stats:        user.Stats,
+		Achievements: s.achievements.Unlocked(channel, username),
+		Rituals:      s.rituals.Rituals(channel, username),
+		Titles:       s.rituals.Titles(channel, username),
+	})
 }
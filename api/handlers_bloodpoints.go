@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"legion-bot-v2/api/dao"
+	"legion-bot-v2/bot/bloodpoints"
+	"log/slog"
+	"net/http"
+)
+
+func (s *Server) handleBloodpointsLedger(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	ledger := s.bot.Ledger(claims.TwitchUser.Login)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ledger)
+}
+
+func (s *Server) handleBloodpointsRefund(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var reqBody dao.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		http.Error(w, "Invalid refund data", http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Refund request",
+		slog.String("channel", claims.TwitchUser.Login),
+		slog.Uint64("id", reqBody.ID),
+	)
+
+	refund, err := s.bot.Refund(claims.TwitchUser.Login, reqBody.ID)
+	if errors.Is(err, bloodpoints.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(refund)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/db"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	channel := claims.TwitchUser.Login
+	customCommands := s.database.GetState(channel).Settings.Commands.Custom
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(customCommands)
+
+	case http.MethodPost:
+		var reqBody db.CustomCommand
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			http.Error(w, "Invalid command data", http.StatusBadRequest)
+			return
+		}
+
+		if slices.ContainsFunc(customCommands, func(c db.CustomCommand) bool { return c.Name == commands.NormalizeName(reqBody.Name) }) {
+			http.Error(w, "Command already exists", http.StatusConflict)
+			return
+		}
+
+		s.saveCustomCommands(w, channel, append(slices.Clone(customCommands), reqBody))
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	channel := claims.TwitchUser.Login
+	name := commands.NormalizeName(r.PathValue("name"))
+	customCommands := slices.Clone(s.database.GetState(channel).Settings.Commands.Custom)
+
+	i := slices.IndexFunc(customCommands, func(c db.CustomCommand) bool { return c.Name == name })
+	if i < 0 {
+		http.Error(w, "Command not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(customCommands[i])
+
+	case http.MethodPut:
+		var reqBody db.CustomCommand
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			http.Error(w, "Invalid command data", http.StatusBadRequest)
+			return
+		}
+
+		customCommands[i] = reqBody
+		s.saveCustomCommands(w, channel, customCommands)
+
+	case http.MethodDelete:
+		s.saveCustomCommands(w, channel, slices.Delete(customCommands, i, i+1))
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) saveCustomCommands(w http.ResponseWriter, channel string, customCommands []db.CustomCommand) {
+	customCommands, err := s.bot.ValidateCustomCommands(customCommands)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Custom commands updated",
+		slog.String("channel", channel),
+		slog.Int("count", len(customCommands)),
+	)
+
+	s.database.UpdateState(channel, func(state *db.ChannelState) {
+		state.Settings.Commands.Custom = customCommands
+	})
+	s.bot.SyncCustomCommands(channel)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customCommands)
+}
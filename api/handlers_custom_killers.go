@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"legion-bot-v2/bot/killer/custom"
+	"legion-bot-v2/db"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+const maxCustomKillerSize = 64 * 1024
+
+func (s *Server) handleCustomKillers(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	channel := claims.TwitchUser.Login
+
+	switch r.Method {
+	case http.MethodGet:
+		chanState := s.database.GetState(channel)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(chanState.Settings.CustomKillers)
+
+	case http.MethodPost:
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxCustomKillerSize))
+		if err != nil {
+			http.Error(w, "Invalid custom killer data", http.StatusBadRequest)
+			return
+		}
+
+		def, err := custom.Parse(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		killers := slices.Clone(s.database.GetState(channel).Settings.CustomKillers)
+		if i := slices.IndexFunc(killers, func(k db.CustomKiller) bool { return k.Name == def.Name }); i >= 0 {
+			killers[i] = def
+		} else {
+			killers = append(killers, def)
+		}
+
+		killers, err = custom.ValidateAll(killers)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		slog.Info("Custom killer uploaded",
+			slog.String("channel", channel),
+			slog.String("killer", def.Name),
+		)
+
+		s.database.UpdateState(channel, func(state *db.ChannelState) {
+			state.Settings.CustomKillers = killers
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(def)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleCustomKiller(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	channel := claims.TwitchUser.Login
+	name := r.PathValue("name")
+
+	killers := s.database.GetState(channel).Settings.CustomKillers
+	if !slices.ContainsFunc(killers, func(k db.CustomKiller) bool { return k.Name == name }) {
+		http.Error(w, "Custom killer not found", http.StatusNotFound)
+		return
+	}
+
+	slog.Info("Custom killer deleted",
+		slog.String("channel", channel),
+		slog.String("killer", name),
+	)
+
+	s.database.UpdateState(channel, func(state *db.ChannelState) {
+		state.Settings.CustomKillers = slices.DeleteFunc(slices.Clone(state.Settings.CustomKillers), func(k db.CustomKiller) bool {
+			return k.Name == name
+		})
+	})
+
+	w.WriteHeader(http.StatusOK)
+}
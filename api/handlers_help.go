@@ -0,0 +1,13 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (s *Server) handleHelp(w http.ResponseWriter, r *http.Request) {
+	channel := r.PathValue("channel")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.bot.Help(channel))
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/db"
+	"log/slog"
+	"maps"
+	"net/http"
+)
+
+type localeKey struct {
+	i18n.KeyInfo
+	Override string `json:"override,omitempty"`
+}
+
+type localeOverrideRequest struct {
+	Text string `json:"text"`
+}
+
+func (s *Server) handleLocale(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	overrides := s.database.GetState(claims.TwitchUser.Login).Settings.LocaleOverrides
+
+	keys := s.localiser.Keys()
+	result := make([]localeKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, localeKey{
+			KeyInfo:  key,
+			Override: overrides[key.Key],
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) handleLocaleKey(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	channel := claims.TwitchUser.Login
+	key := r.PathValue("key")
+	overrides := maps.Clone(s.database.GetState(channel).Settings.LocaleOverrides)
+	if overrides == nil {
+		overrides = make(map[string]string)
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var reqBody localeOverrideRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			http.Error(w, "Invalid override data", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.localiser.ValidateOverride(key, reqBody.Text); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		overrides[key] = reqBody.Text
+
+	case http.MethodDelete:
+		if _, ok := overrides[key]; !ok {
+			http.Error(w, "Override not found", http.StatusNotFound)
+			return
+		}
+
+		delete(overrides, key)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	slog.Info("Locale overrides updated",
+		slog.String("channel", channel),
+		slog.String("key", key),
+		slog.Int("count", len(overrides)),
+	)
+
+	s.database.UpdateState(channel, func(state *db.ChannelState) {
+		state.Settings.LocaleOverrides = overrides
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(overrides)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"legion-bot-v2/api/dao"
+	"legion-bot-v2/bot/punishments"
+	"legion-bot-v2/db"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+func (s *Server) handlePunishments(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	channel := claims.TwitchUser.Login
+	outstanding := s.bot.Outstanding(channel)
+
+	entries := make([]dao.PunishmentEntry, 0)
+	for _, punishment := range s.bot.Punishments(channel) {
+		entries = append(entries, dao.PunishmentEntry{
+			Punishment:  punishment,
+			Outstanding: slices.ContainsFunc(outstanding, func(p db.Punishment) bool { return p.ID == punishment.ID }),
+		})
+	}
+
+	// the most recent actions first
+	slices.Reverse(entries)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func (s *Server) handlePunishmentRevert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var reqBody dao.RevertRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		http.Error(w, "Invalid revert data", http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Punishment revert request",
+		slog.String("channel", claims.TwitchUser.Login),
+		slog.Uint64("id", reqBody.ID),
+	)
+
+	revert, err := s.bot.Revert(claims.TwitchUser.Login, reqBody.ID, punishments.ReasonDashboard)
+	if errors.Is(err, punishments.ErrEntryNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revert)
+}
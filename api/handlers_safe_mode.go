@@ -0,0 +1,26 @@
+package api
+
+import (
+	"encoding/json"
+	"legion-bot-v2/db"
+	"net/http"
+	"slices"
+)
+
+func (s *Server) handleSafeMode(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// the most recent actions first
+	actions := slices.Clone(s.database.GetState(claims.TwitchUser.Login).SafeModeLog)
+	slices.Reverse(actions)
+	if actions == nil {
+		actions = []db.SafeModeAction{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(actions)
+}
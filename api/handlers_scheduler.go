@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"legion-bot-v2/api/dao"
+	"log/slog"
+	"net/http"
+)
+
+func (s *Server) handleSchedulerPreview(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	channel := claims.TwitchUser.Login
+	preview := s.bot.Preview(channel, s.bot.GetCachedStreamStartTime(channel))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+func (s *Server) handleSchedulerQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var reqBody dao.KillerQueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		http.Error(w, "Invalid queue data", http.StatusBadRequest)
+		return
+	}
+
+	slog.Info("Killer queue updated",
+		slog.String("channel", claims.TwitchUser.Login),
+		slog.Any("queue", reqBody.Queue),
+	)
+
+	if err := s.bot.SetQueue(claims.TwitchUser.Login, reqBody.Queue); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
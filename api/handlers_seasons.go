@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"legion-bot-v2/api/dao"
+	"net/http"
+	"strconv"
+)
+
+func (s *Server) handleSeasonList(w http.ResponseWriter, r *http.Request) {
+	channel := r.PathValue("channel")
+
+	seasons := s.database.GetSeasons(channel)
+
+	result := make([]dao.SeasonSummary, 0, len(seasons))
+	for _, season := range seasons {
+		summary := dao.SeasonSummary{
+			ID:           season.ID,
+			Start:        season.Start,
+			End:          season.End,
+			Participants: len(season.Standings),
+		}
+
+		if len(season.Leaderboard) > 0 {
+			summary.Winner = &season.Leaderboard[0]
+		}
+
+		result = append(result, summary)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) handleSeason(w http.ResponseWriter, r *http.Request) {
+	channel := r.PathValue("channel")
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid season id", http.StatusBadRequest)
+		return
+	}
+
+	for _, season := range s.database.GetSeasons(channel) {
+		if season.ID == id {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(season)
+			return
+		}
+	}
+
+	http.Error(w, "Season not found", http.StatusNotFound)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"legion-bot-v2/api/dao"
+	"legion-bot-v2/db"
+	"net/http"
+)
+
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	claims, err := s.authenticateRequest(r)
+	if err != nil {
+		http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chanState := s.database.GetState(claims.TwitchUser.Login)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dao.SessionResponse{
+		Pressure: s.bot.Pressure(chanState.Channel),
+		Current:  formatSessionInfo(chanState.Settings, chanState.Session),
+		Last:     formatSessionInfo(chanState.Settings, chanState.LastSession),
+	})
+}
+
+func formatSessionInfo(settings db.Settings, session db.SessionState) *dao.SessionInfo {
+	if session.Killer == "" {
+		return nil
+	}
+
+	effective := db.ApplyModifiers(settings.Killers, session.Killer, session.Modifiers)
+
+	return &dao.SessionInfo{
+		Killer:       session.Killer,
+		Start:        session.Start,
+		Participants: len(session.Participants),
+		Pressure:     session.Pressure,
+		Modifiers:    session.Modifiers,
+		AddOns:       session.AddOns,
+		Clips:        session.Clips,
+		Effective:    effective.ByName(session.Killer),
+	}
+}
@@ -185,3 +185,61 @@ func (s *Server) handleStreamEnd(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("ok"))
 }
+
+func (s *Server) handleChannelUpdate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Error("Failed to read channel update body",
+			slog.Any("error", err),
+		)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+		return
+	}
+	defer r.Body.Close()
+
+	if !helix.VerifyEventSubNotification(s.cfg.Twitch.WebHookSecret, r.Header, string(body)) {
+		slog.Error("Invalid signature for channel update")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var eventDao dao.EventSubNotification
+	err = json.NewDecoder(bytes.NewReader(body)).Decode(&eventDao)
+	if err != nil {
+		slog.Error("Failed to parse channel update general body",
+			slog.Any("error", err),
+		)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+		return
+	}
+
+	if eventDao.Challenge != "" {
+		w.Write([]byte(eventDao.Challenge))
+		return
+	}
+
+	var event helix.EventSubChannelUpdateEvent
+	err = json.NewDecoder(bytes.NewReader(eventDao.Event)).Decode(&event)
+	if err != nil {
+		slog.Error("Failed to decode channel update body",
+			slog.Any("error", err),
+		)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+		return
+	}
+
+	channel := strings.ToLower(strings.ReplaceAll(event.BroadcasterUserLogin, "#", ""))
+
+	slog.Info("Channel update",
+		slog.String("channel", channel),
+		slog.String("category", event.CategoryName),
+	)
+
+	go s.bot.HandleCategoryUpdate(channel, event.CategoryName)
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
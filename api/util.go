@@ -3,13 +3,17 @@ package api
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"github.com/elliotchance/pie/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"legion-bot-v2/api/dao"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/killer/custom"
+	"legion-bot-v2/bot/permissions"
 	"legion-bot-v2/db"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 )
@@ -71,6 +75,77 @@ func generateRandomString(length int) (string, error) {
 }
 
 func (s *Server) formatChannelStatus(chanState db.ChannelState) dao.ChannelStatusResponse {
+	lang := chanState.Settings.Language
+	status := s.formatKillerStatus(chanState)
+	status.Budget = formatBudgetStatus(chanState)
+	status.Warnings = s.formatPermissionWarnings(chanState)
+
+	if chanState.Killer != "" || status.Status == dao.ChannelStatusError {
+		return status
+	}
+
+	preview := s.bot.Preview(chanState.Channel, s.bot.GetCachedStreamStartTime(chanState.Channel))
+
+	if len(preview.Queue) > 0 && preview.NextKiller != "" {
+		status.NextKiller = s.localiser.GetLocalString(lang, "killer_"+preview.NextKiller, nil)
+	}
+
+	if !preview.NextETA.IsZero() {
+		status.NextKillerETA = max(time.Until(preview.NextETA), 0)
+	}
+
+	return status
+}
+
+// formatPermissionWarnings explains which enabled killers can't run because of the missing token scopes
+func (s *Server) formatPermissionWarnings(chanState db.ChannelState) []string {
+	lang := chanState.Settings.Language
+	warnings := make([]string, 0)
+
+	for _, k := range pie.Values(s.killerMap) {
+		if !k.Enabled(chanState.Channel) {
+			continue
+		}
+
+		var missingScopes *permissions.MissingScopesError
+		if !errors.As(s.bot.Check(chanState.Channel, k), &missingScopes) {
+			continue
+		}
+
+		warnings = append(warnings, s.localiser.GetLocalString(lang, "channel_status_missing_scopes", map[string]string{
+			"KILLER": s.localiser.GetLocalString(lang, "killer_"+k.Name(), nil),
+			"SCOPES": strings.Join(missingScopes.Scopes, ", "),
+		}))
+	}
+
+	slices.Sort(warnings)
+
+	return warnings
+}
+
+func formatBudgetStatus(chanState db.ChannelState) *dao.BudgetStatus {
+	settings := chanState.Settings.Budget
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+
+	budget := chanState.Budget
+	sessionUsers := 0
+	if budget.SessionStart.Equal(chanState.Session.Start) {
+		sessionUsers = len(budget.SessionUsers)
+	}
+
+	return &dao.BudgetStatus{
+		Spent:             budget.Spent,
+		StreamTimeout:     settings.StreamTimeout,
+		Users:             len(budget.Users),
+		SessionUsers:      sessionUsers,
+		SessionUsersLimit: settings.SessionUsers,
+		Skipped:           budget.Skipped,
+	}
+}
+
+func (s *Server) formatKillerStatus(chanState db.ChannelState) dao.ChannelStatusResponse {
 	lang := chanState.Settings.Language
 	diff := time.Now().Sub(chanState.Date)
 	generalKillerSettings := chanState.Settings.Killers.General
@@ -94,6 +169,9 @@ func (s *Server) formatChannelStatus(chanState db.ChannelState) dao.ChannelStatu
 
 	if chanState.Killer != "" {
 		killerName := s.localiser.GetLocalString(lang, "killer_"+chanState.Killer, nil)
+		if def, _, ok := custom.Current(chanState); ok {
+			killerName = def.DisplayName
+		}
 
 		var timeRemaining time.Duration
 
@@ -121,6 +199,14 @@ func (s *Server) formatChannelStatus(chanState db.ChannelState) dao.ChannelStatu
 		}
 	}
 
+	if errors.Is(s.bot.Check(chanState.Channel, killerList[0]), permissions.ErrNotModerator) {
+		return dao.ChannelStatusResponse{
+			Status:   dao.ChannelStatusError,
+			Title:    s.localiser.GetLocalString(lang, "channel_status_not_moderator", nil),
+			Subtitle: s.localiser.GetLocalString(lang, "channel_status_not_moderator_subtitle", nil),
+		}
+	}
+
 	if diff <= generalKillerSettings.DelayBetweenKillers {
 		return dao.ChannelStatusResponse{
 			Status:        dao.ChannelStatusIdle,
@@ -145,6 +231,14 @@ func (s *Server) formatChannelStatus(chanState db.ChannelState) dao.ChannelStatu
 		}
 	}
 
+	if !s.bot.CategoryAllowed(chanState.Channel) {
+		return dao.ChannelStatusResponse{
+			Status:   dao.ChannelStatusIdle,
+			Title:    s.localiser.GetLocalString(lang, "channel_status_wrong_category", nil),
+			Subtitle: s.localiser.GetLocalString(lang, "channel_status_wrong_category_subtitle", map[string]string{"CATEGORY": s.bot.GetCachedCategory(chanState.Channel)}),
+		}
+	}
+
 	if streamLength <= generalKillerSettings.DelayAtTheStreamStart {
 		return dao.ChannelStatusResponse{
 			Status:        dao.ChannelStatusIdle,
@@ -165,6 +259,15 @@ func (s *Server) formatChannelStatus(chanState db.ChannelState) dao.ChannelStatu
 		}
 	}
 
+	if now := time.Now(); !s.bot.InWindow(chanState.Channel, now) {
+		return dao.ChannelStatusResponse{
+			Status:        dao.ChannelStatusIdle,
+			Title:         s.localiser.GetLocalString(lang, "channel_status_outside_window", nil),
+			Subtitle:      s.localiser.GetLocalString(lang, "time_remaining_subtitle", nil),
+			TimeRemaining: s.bot.NextWindow(chanState.Channel, now).Sub(now),
+		}
+	}
+
 	return dao.ChannelStatusResponse{
 		Status:   dao.ChannelStatusSuccess,
 		Title:    s.localiser.GetLocalString(lang, "channel_status_success", nil),
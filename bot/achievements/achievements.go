@@ -0,0 +1,195 @@
+package achievements
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+//go:embed achievements.json
+var definitionsData []byte
+
+type Definition struct {
+	ID     string            `json:"id"`
+	Stat   string            `json:"stat"`
+	Event  events.Type       `json:"event"`
+	Killer string            `json:"killer"`
+	Detail string            `json:"detail"`
+	Count  int               `json:"count"`
+	Name   map[string]string `json:"name"`
+}
+
+func (d Definition) LocalName(lang string) string {
+	if name, ok := d.Name[lang]; ok {
+		return name
+	}
+	if name, ok := d.Name["en"]; ok {
+		return name
+	}
+	return d.ID
+}
+
+func (d Definition) matchesEvent(event events.Event) bool {
+	if d.Event == "" || d.Event != event.Type {
+		return false
+	}
+	if d.Killer != "" && d.Killer != event.Killer {
+		return false
+	}
+	if d.Detail != "" && d.Detail != event.Detail {
+		return false
+	}
+	return true
+}
+
+type Unlocked struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	UnlockedAt time.Time `json:"unlockedAt"`
+}
+
+type Engine interface {
+	Definitions() []Definition
+	Unlocked(channel, username string) []Unlocked
+}
+
+var _ Engine = (*Impl)(nil)
+
+type Impl struct {
+	db.DB
+	chat.Actions
+	i18n.Localiser
+	definitions []Definition
+}
+
+func New(di *do.Injector) (Engine, error) {
+	var definitions []Definition
+	if err := json.Unmarshal(definitionsData, &definitions); err != nil {
+		return nil, fmt.Errorf("error parsing achievements: %v", err)
+	}
+
+	for _, d := range definitions {
+		if d.ID == "" || d.Count <= 0 || (d.Stat == "") == (d.Event == "") {
+			return nil, fmt.Errorf("invalid achievement definition %q", d.ID)
+		}
+	}
+
+	engine := &Impl{
+		DB:          do.MustInvoke[db.DB](di),
+		Actions:     do.MustInvoke[chat.Actions](di),
+		Localiser:   do.MustInvoke[i18n.Localiser](di),
+		definitions: definitions,
+	}
+
+	do.MustInvoke[events.Bus](di).Subscribe(engine.handleEvent)
+
+	return engine, nil
+}
+
+func (e *Impl) Definitions() []Definition {
+	return e.definitions
+}
+
+func (e *Impl) Unlocked(channel, username string) []Unlocked {
+	chanState := e.GetState(channel)
+	lang := chanState.Settings.Language
+
+	result := make([]Unlocked, 0)
+
+	user, ok := chanState.UserMap[username]
+	if !ok {
+		return result
+	}
+
+	for _, d := range e.definitions {
+		unlockedAt, ok := user.Achievements[d.ID]
+		if !ok {
+			continue
+		}
+
+		result = append(result, Unlocked{
+			ID:         d.ID,
+			Name:       d.LocalName(lang),
+			UnlockedAt: unlockedAt,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UnlockedAt.Before(result[j].UnlockedAt)
+	})
+
+	return result
+}
+
+func (e *Impl) handleEvent(event events.Event) {
+	if event.Username == "" {
+		return
+	}
+
+	chanState := e.GetState(event.Channel)
+	lang := chanState.Settings.Language
+	now := time.Now()
+
+	var unlocked []Definition
+
+	e.UpdateState(event.Channel, func(chanState *db.ChannelState) {
+		user, ok := chanState.UserMap[event.Username]
+		if !ok {
+			return
+		}
+
+		if user.Achievements == nil {
+			user.Achievements = make(map[string]time.Time)
+		}
+		if user.AchievementProgress == nil {
+			user.AchievementProgress = make(map[string]int)
+		}
+
+		for _, d := range e.definitions {
+			if _, ok := user.Achievements[d.ID]; ok {
+				continue
+			}
+
+			var progress int
+
+			if d.Stat != "" {
+				progress = user.Stats[d.Stat]
+			} else if d.matchesEvent(event) {
+				user.AchievementProgress[d.ID]++
+				progress = user.AchievementProgress[d.ID]
+			} else {
+				continue
+			}
+
+			if progress < d.Count {
+				continue
+			}
+
+			user.Achievements[d.ID] = now
+			delete(user.AchievementProgress, d.ID)
+			unlocked = append(unlocked, d)
+		}
+	})
+
+	for _, d := range unlocked {
+		slog.Info("Achievement unlocked",
+			slog.String("channel", event.Channel),
+			slog.String("username", event.Username),
+			slog.String("id", d.ID),
+		)
+
+		msg := e.GetChannelString(chanState.Channel, "achievement_unlocked", map[string]string{
+			"USERNAME": event.Username,
+			"NAME":     d.LocalName(lang),
+		})
+		e.SendMessage(event.Channel, msg)
+	}
+}
@@ -0,0 +1,219 @@
+package addons
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/db"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+//go:embed addons.json
+var definitionsData []byte
+
+var rarityWeights = map[string]int{
+	"common":     40,
+	"uncommon":   30,
+	"rare":       15,
+	"very_rare":  10,
+	"ultra_rare": 5,
+}
+
+// addOnCountWeights are the weights of rolling 0, 1 or 2 add-ons for a session
+var addOnCountWeights = []int{30, 45, 25}
+
+// Effects of the add-ons that can't be expressed as setting modifiers, the killers register what they do
+const (
+	EffectMarkOnHit              = "mark_on_hit"
+	EffectRevealDeepWoundedOnHit = "reveal_deep_wounded_on_hit"
+)
+
+var knownEffects = []string{EffectMarkOnHit, EffectRevealDeepWoundedOnHit}
+
+type Definition struct {
+	ID        string               `json:"id"`
+	Killer    string               `json:"killer"`
+	Rarity    string               `json:"rarity"`
+	Name      map[string]string    `json:"name"`
+	Modifiers []db.SettingModifier `json:"modifiers"`
+	Effects   []string             `json:"effects"`
+}
+
+func (d Definition) LocalName(lang string) string {
+	if name, ok := d.Name[lang]; ok {
+		return name
+	}
+	if name, ok := d.Name["en"]; ok {
+		return name
+	}
+	return d.ID
+}
+
+type Catalog interface {
+	AddOns(killerName string) []Definition
+	AddOn(id string) (Definition, bool)
+	RollAddOns(channel, killerName string) []Definition
+	// OnEffect calls the listener for events of the given type in sessions with an add-on that has the effect
+	OnEffect(effect string, eventType events.Type, listener events.Listener)
+}
+
+var _ Catalog = (*Impl)(nil)
+
+type effectKey struct {
+	effect    string
+	eventType events.Type
+}
+
+type Impl struct {
+	db.DB
+	definitions []Definition
+
+	mutex     sync.RWMutex
+	listeners map[effectKey][]events.Listener
+}
+
+func New(di *do.Injector) (Catalog, error) {
+	var definitions []Definition
+	if err := json.Unmarshal(definitionsData, &definitions); err != nil {
+		return nil, fmt.Errorf("error parsing add-ons: %v", err)
+	}
+
+	killers := db.DefaultSettings().Killers
+
+	for _, d := range definitions {
+		if _, ok := rarityWeights[d.Rarity]; !ok || d.ID == "" || len(d.Modifiers)+len(d.Effects) == 0 {
+			return nil, fmt.Errorf("invalid add-on definition %q", d.ID)
+		}
+
+		for _, effect := range d.Effects {
+			if !slices.Contains(knownEffects, effect) {
+				return nil, fmt.Errorf("add-on %q has unknown effect %q", d.ID, effect)
+			}
+		}
+
+		for _, m := range d.Modifiers {
+			if !killers.HasField(d.Killer, m.Field) {
+				return nil, fmt.Errorf("add-on %q modifies unknown field %q of %q", d.ID, m.Field, d.Killer)
+			}
+		}
+	}
+
+	catalog := &Impl{
+		DB:          do.MustInvoke[db.DB](di),
+		definitions: definitions,
+		listeners:   make(map[effectKey][]events.Listener),
+	}
+
+	do.MustInvoke[events.Bus](di).Subscribe(catalog.handleEvent)
+
+	return catalog, nil
+}
+
+func (c *Impl) AddOns(killerName string) []Definition {
+	result := make([]Definition, 0)
+	for _, d := range c.definitions {
+		if d.Killer == killerName {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+func (c *Impl) AddOn(id string) (Definition, bool) {
+	for _, d := range c.definitions {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+func (c *Impl) RollAddOns(channel, killerName string) []Definition {
+	chanState := c.GetState(channel)
+	addOnSettings := chanState.Settings.AddOns
+
+	if addOnSettings == nil || !addOnSettings.Enabled {
+		return nil
+	}
+
+	pool := slices.DeleteFunc(c.AddOns(killerName), func(d Definition) bool {
+		return slices.Contains(addOnSettings.Banned, d.ID)
+	})
+
+	count := pickWeighted(addOnCountWeights)
+
+	var result []Definition
+	for len(result) < count && len(pool) > 0 {
+		weights := make([]int, len(pool))
+		for i, d := range pool {
+			weights[i] = rarityWeights[d.Rarity]
+		}
+
+		i := pickWeighted(weights)
+		result = append(result, pool[i])
+		pool = slices.Delete(pool, i, i+1)
+	}
+
+	return result
+}
+
+func (c *Impl) OnEffect(effect string, eventType events.Type, listener events.Listener) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := effectKey{effect: effect, eventType: eventType}
+	c.listeners[key] = append(c.listeners[key], listener)
+}
+
+func (c *Impl) handleEvent(event events.Event) {
+	session := c.GetState(event.Channel).Session
+	if event.Killer == "" || session.Killer != event.Killer {
+		return
+	}
+
+	var listeners []events.Listener
+
+	c.mutex.RLock()
+	for _, id := range session.AddOns {
+		d, ok := c.AddOn(id)
+		if !ok {
+			continue
+		}
+
+		for _, effect := range d.Effects {
+			listeners = append(listeners, c.listeners[effectKey{effect: effect, eventType: event.Type}]...)
+		}
+	}
+	c.mutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+func pickWeighted(weights []int) int {
+	totalWeight := 0
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+
+	if totalWeight <= 0 {
+		return 0
+	}
+
+	r := rand.IntN(totalWeight)
+
+	runningTotal := 0
+	for i, weight := range weights {
+		runningTotal += weight
+		if r < runningTotal {
+			return i
+		}
+	}
+
+	return len(weights) - 1
+}
@@ -0,0 +1,154 @@
+package bloodpoints
+
+import (
+	"errors"
+	"fmt"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/db"
+	"log/slog"
+)
+
+var (
+	ErrNotEnoughBloodpoints = errors.New("not enough bloodpoints")
+	ErrEntryNotFound        = db.ErrLedgerEntryNotFound
+	ErrAlreadyRefunded      = db.ErrAlreadyRefunded
+)
+
+type Bank interface {
+	Balance(channel, username string) int
+	Earn(channel, username string, amount int, reason string)
+	// Spend takes the bloodpoints from the user and returns the purchase entry of the ledger
+	Spend(channel, username string, amount int, reason string) (db.LedgerEntry, error)
+	Refund(channel string, id uint64) (db.LedgerEntry, error)
+	Ledger(channel string) []db.LedgerEntry
+}
+
+var _ Bank = (*Impl)(nil)
+
+type Impl struct {
+	db.DB
+}
+
+func New(di *do.Injector) Bank {
+	bank := &Impl{
+		DB: do.MustInvoke[db.DB](di),
+	}
+
+	do.MustInvoke[events.Bus](di).Subscribe(bank.handleEvent)
+
+	return bank
+}
+
+func (b *Impl) handleEvent(event events.Event) {
+	if event.Username == "" {
+		return
+	}
+
+	chanState := b.GetState(event.Channel)
+	bpSettings := chanState.Settings.Bloodpoints
+
+	if bpSettings == nil || !bpSettings.Enabled {
+		return
+	}
+
+	var amount int
+
+	switch event.Type {
+	case events.TypeHeal:
+		amount = bpSettings.HealReward
+	case events.TypeUnhook:
+		amount = bpSettings.UnhookReward
+	case events.TypeStun:
+		amount = bpSettings.StunReward
+	case events.TypeReveal:
+		amount = bpSettings.RevealReward
+	case events.TypeSurvive:
+		amount = bpSettings.SurviveReward
+	default:
+		return
+	}
+
+	if amount <= 0 {
+		return
+	}
+
+	b.Earn(event.Channel, event.Username, amount, string(event.Type))
+}
+
+func (b *Impl) Balance(channel, username string) int {
+	chanState := b.GetState(channel)
+
+	user, ok := chanState.UserMap[username]
+	if !ok {
+		return 0
+	}
+
+	return user.Bloodpoints
+}
+
+func (b *Impl) Earn(channel, username string, amount int, reason string) {
+	b.UpdateState(channel, func(chanState *db.ChannelState) {
+		if _, ok := chanState.UserMap[username]; !ok {
+			chanState.UserMap[username] = db.NewUser()
+		}
+
+		chanState.UserMap[username].Bloodpoints += amount
+	})
+
+	_, _ = b.AppendLedgerEntry(channel, db.LedgerEntry{
+		Username: username,
+		Kind:     db.LedgerEntryEarn,
+		Reason:   reason,
+		Amount:   amount,
+	})
+}
+
+func (b *Impl) Spend(channel, username string, amount int, reason string) (db.LedgerEntry, error) {
+	var spendErr error
+
+	b.UpdateState(channel, func(chanState *db.ChannelState) {
+		user, ok := chanState.UserMap[username]
+		if !ok || user.Bloodpoints < amount {
+			spendErr = ErrNotEnoughBloodpoints
+			return
+		}
+
+		user.Bloodpoints -= amount
+	})
+
+	if spendErr != nil {
+		return db.LedgerEntry{}, spendErr
+	}
+
+	purchase, err := b.AppendLedgerEntry(channel, db.LedgerEntry{
+		Username: username,
+		Kind:     db.LedgerEntryPurchase,
+		Reason:   reason,
+		Amount:   -amount,
+	})
+	if err != nil {
+		return db.LedgerEntry{}, fmt.Errorf("failed to record purchase: %w", err)
+	}
+
+	return purchase, nil
+}
+
+func (b *Impl) Refund(channel string, id uint64) (db.LedgerEntry, error) {
+	refund, err := b.RefundLedgerEntry(channel, id)
+	if err != nil {
+		return db.LedgerEntry{}, err
+	}
+
+	slog.Info("Bloodpoints refunded",
+		slog.String("channel", channel),
+		slog.String("username", refund.Username),
+		slog.Int("amount", refund.Amount),
+	)
+
+	return refund, nil
+}
+
+func (b *Impl) Ledger(channel string) []db.LedgerEntry {
+	return b.GetLedgerEntries(channel)
+}
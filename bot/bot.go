@@ -7,15 +7,25 @@ import (
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/samber/do"
 	"legion-bot-v2/api/dao"
+	"legion-bot-v2/bot/addons"
+	"legion-bot-v2/bot/bloodpoints"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/bot/greetings"
+	"legion-bot-v2/bot/highlights"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/permissions"
+	"legion-bot-v2/bot/punishments"
+	"legion-bot-v2/bot/rituals"
+	"legion-bot-v2/bot/scheduler"
+	"legion-bot-v2/config"
 	"legion-bot-v2/db"
 	"legion-bot-v2/gpt"
 	"legion-bot-v2/twitch/chat"
 	"legion-bot-v2/util"
 	"legion-bot-v2/util/timers"
 	"log/slog"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -26,9 +36,22 @@ type Bot struct {
 	timers.Timers
 	i18n.Localiser
 	gpt.Gpt
+	events.Bus
+	bloodpoints.Bank
+	rituals.Engine
+	greetings.Greeter
+	highlights.Highlights
+	permissions.Checker
+	punishments.Tracker
+	scheduler.Scheduler
+	addons.Catalog
+	commands.Registry
+	cfg            *config.Config
 	killerMap      map[string]killer.Killer
 	streamStartMap *ttlcache.Cache[string, time.Time]
 	viewerCountMap *ttlcache.Cache[string, int]
+	categoryMap    *ttlcache.Cache[string, string]
+	messageRate    *messageRate
 }
 
 func NewBot(di *do.Injector) *Bot {
@@ -43,17 +66,39 @@ func NewBot(di *do.Injector) *Bot {
 	)
 	go viewerCountMap.Start()
 
+	categoryMap := ttlcache.New[string, string](
+		ttlcache.WithTTL[string, string](10*time.Minute),
+		ttlcache.WithDisableTouchOnHit[string, string](),
+	)
+	go categoryMap.Start()
+
 	bot := &Bot{
 		DB:             do.MustInvoke[db.DB](di),
 		Actions:        do.MustInvoke[chat.Actions](di),
 		Timers:         do.MustInvoke[timers.Timers](di),
 		Localiser:      do.MustInvoke[i18n.Localiser](di),
 		Gpt:            do.MustInvoke[gpt.Gpt](di),
+		Bus:            do.MustInvoke[events.Bus](di),
+		Bank:           do.MustInvoke[bloodpoints.Bank](di),
+		Engine:         do.MustInvoke[rituals.Engine](di),
+		Greeter:        do.MustInvoke[greetings.Greeter](di),
+		Highlights:     do.MustInvoke[highlights.Highlights](di),
+		Checker:        do.MustInvoke[permissions.Checker](di),
+		Tracker:        do.MustInvoke[punishments.Tracker](di),
+		Scheduler:      do.MustInvoke[scheduler.Scheduler](di),
+		Catalog:        do.MustInvoke[addons.Catalog](di),
+		Registry:       do.MustInvoke[commands.Registry](di),
+		cfg:            do.MustInvoke[*config.Config](di),
 		killerMap:      do.MustInvoke[map[string]killer.Killer](di),
 		streamStartMap: streamStartMap,
 		viewerCountMap: viewerCountMap,
+		categoryMap:    categoryMap,
+		messageRate:    newMessageRate(),
 	}
 
+	bot.Subscribe(bot.handleEvent)
+	bot.registerCommands()
+
 	return bot
 }
 
@@ -68,10 +113,76 @@ func (b *Bot) Init() {
 				chanState.Date = time.Now()
 			}
 
+			chanState.Session = db.SessionState{}
+
 			if chanState.Settings.Killers.General == nil {
 				chanState.Settings.Killers.General = db.DefaultGeneralKillerSettings()
 			}
 
+			if chanState.Settings.Killers.General.AllowedCategories == nil {
+				chanState.Settings.Killers.General.AllowedCategories = db.DefaultAllowedCategories()
+			}
+
+			if chanState.Settings.Killers.Scheduler == nil {
+				chanState.Settings.Killers.Scheduler = db.DefaultSchedulerSettings()
+			}
+
+			if chanState.Settings.Bloodpoints == nil {
+				chanState.Settings.Bloodpoints = db.DefaultBloodpointsSettings()
+			}
+
+			if chanState.Settings.Seasons == nil {
+				chanState.Settings.Seasons = db.DefaultSeasonsSettings()
+			}
+
+			if chanState.Settings.Difficulty == nil {
+				chanState.Settings.Difficulty = db.DefaultDifficultySettings()
+			}
+
+			if chanState.Settings.AddOns == nil {
+				chanState.Settings.AddOns = db.DefaultAddOnsSettings()
+			}
+
+			if chanState.Settings.Rituals == nil {
+				chanState.Settings.Rituals = db.DefaultRitualsSettings()
+			}
+
+			if chanState.Settings.Commands == nil {
+				chanState.Settings.Commands = db.DefaultCommandsSettings()
+			}
+
+			if chanState.Settings.Greetings == nil {
+				chanState.Settings.Greetings = db.DefaultGreetingsSettings()
+			}
+
+			if chanState.Settings.Clips == nil {
+				chanState.Settings.Clips = db.DefaultClipsSettings()
+			}
+
+			if chanState.Settings.Protection == nil {
+				chanState.Settings.Protection = db.DefaultProtectionSettings()
+			}
+
+			if chanState.Settings.Budget == nil {
+				chanState.Settings.Budget = db.DefaultBudgetSettings()
+			}
+
+			if chanState.Settings.Commands.Custom == nil {
+				chanState.Settings.Commands.Custom = []db.CustomCommand{}
+			}
+
+			if chanState.Settings.LocaleOverrides == nil {
+				chanState.Settings.LocaleOverrides = make(map[string]string)
+			}
+
+			if chanState.Settings.CustomKillers == nil {
+				chanState.Settings.CustomKillers = []db.CustomKiller{}
+			}
+
+			if chanState.Settings.Timezone == "" {
+				chanState.Settings.Timezone = "UTC"
+			}
+
 			for _, k := range b.killerMap {
 				k.FixSettings(chanState)
 			}
@@ -82,6 +193,11 @@ func (b *Bot) Init() {
 				}
 			}
 		})
+
+		b.SyncCustomCommands(channel)
+
+		// the game state is gone, so are the reasons of the running timeouts and of the emote-only mode
+		b.Reconcile(channel, punishments.ReasonStartup)
 	}
 }
 
@@ -111,6 +227,43 @@ func (b *Bot) GetCachedViewerCount(channel string) int {
 	return count
 }
 
+func (b *Bot) GetCachedCategory(channel string) string {
+	item := b.categoryMap.Get(channel)
+	if item != nil {
+		return item.Value()
+	}
+
+	category, err := b.GetCategory(channel)
+	if err != nil {
+		slog.Error("Failed to get channel category",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		// failures are not cached, so the next call asks Twitch again
+		return ""
+	}
+
+	b.categoryMap.Set(channel, category, ttlcache.DefaultTTL)
+
+	return category
+}
+
+// CategoryAllowed reports whether the channel's current category is in its allowlist, an empty allowlist allows any category.
+func (b *Bot) CategoryAllowed(channel string) bool {
+	chanState := b.GetState(channel)
+	allowedCategories := chanState.Settings.Killers.General.AllowedCategories
+
+	if len(allowedCategories) == 0 {
+		return true
+	}
+
+	category := b.GetCachedCategory(channel)
+
+	return pie.Any(allowedCategories, func(allowed string) bool {
+		return strings.EqualFold(strings.TrimSpace(allowed), category)
+	})
+}
+
 func (b *Bot) HandleMessage(userMsg db.Message) {
 	chanState := b.GetState(userMsg.Channel)
 	generalKillerSettings := chanState.Settings.Killers.General
@@ -123,6 +276,8 @@ func (b *Bot) HandleMessage(userMsg db.Message) {
 		return
 	}
 
+	b.messageRate.Record(userMsg.Channel)
+
 	streamStartTime := b.GetCachedStreamStartTime(userMsg.Channel)
 
 	var streamLength time.Duration
@@ -138,6 +293,26 @@ func (b *Bot) HandleMessage(userMsg db.Message) {
 		})
 	}
 
+	// roles are remembered for the protection policy, which is also applied outside of message handling
+	roles := db.UserRoles{IsMod: userMsg.IsMod, IsVIP: userMsg.IsVIP, SubTier: userMsg.SubTier}
+	if user.Roles != roles {
+		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+			chanState.UserMap[userMsg.Username].Roles = roles
+		})
+	}
+
+	b.Greet(userMsg, !userExists, streamStartTime)
+
+	if chanState.Killer != "" && !chanState.Session.Participants[userMsg.Username] {
+		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+			if chanState.Session.Participants == nil {
+				chanState.Session.Participants = make(map[string]bool)
+			}
+
+			chanState.Session.Participants[userMsg.Username] = true
+		})
+	}
+
 	if b.HandleCommands(userMsg) {
 		return
 	}
@@ -170,8 +345,12 @@ func (b *Bot) HandleMessage(userMsg db.Message) {
 }
 
 func (b *Bot) HandleStreamOnline(channel string) {
+	// the moderation budget is per stream
+	b.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.Budget = db.BudgetState{}
+	})
+
 	chanState := b.GetState(channel)
-	lang := chanState.Settings.Language
 
 	if chanState.Settings.Disabled || time.Now().Before(chanState.UserTimeout) {
 		slog.Debug("HandleStreamOnline ignored",
@@ -182,14 +361,14 @@ func (b *Bot) HandleStreamOnline(channel string) {
 	}
 
 	b.GetCachedStreamStartTime(channel)
+	b.categoryMap.Delete(channel)
 
-	msg := b.GetLocalString(lang, "stream_start_greeting", map[string]string{})
+	msg := b.GetChannelString(channel, "stream_start_greeting", map[string]string{})
 	b.SendMessage(channel, msg)
 }
 
 func (b *Bot) HandleStreamOffline(channel string) {
 	chanState := b.GetState(channel)
-	lang := chanState.Settings.Language
 
 	if chanState.Settings.Disabled || time.Now().Before(chanState.UserTimeout) {
 		slog.Debug("HandleStreamOffline ignored",
@@ -201,10 +380,14 @@ func (b *Bot) HandleStreamOffline(channel string) {
 
 	b.streamStartMap.Delete(channel)
 
-	msg := b.GetLocalString(lang, "stream_end_greeting", map[string]string{})
+	msg := b.GetChannelString(channel, "stream_end_greeting", map[string]string{})
 	b.SendMessage(channel, msg)
 }
 
+func (b *Bot) HandleCategoryUpdate(channel, category string) {
+	b.categoryMap.Set(channel, category, ttlcache.DefaultTTL)
+}
+
 func (b *Bot) HandleWhisper(username, message string) {
 	channels := b.GetAllChannelNames()
 
@@ -269,7 +452,6 @@ func (b *Bot) HandleOutgoingRaid(channel, otherChannel string) {
 
 func (b *Bot) HandleNewSteamComment(channel string, comment dao.Comment) {
 	chanState := b.GetState(channel)
-	lang := chanState.Settings.Language
 	steamSettings := chanState.Settings.Steam
 
 	if chanState.Settings.Disabled ||
@@ -278,7 +460,7 @@ func (b *Bot) HandleNewSteamComment(channel string, comment dao.Comment) {
 		return
 	}
 
-	msg := b.GetLocalString(lang, "steam_new_comment", map[string]string{"CHANNEL": channel})
+	msg := b.GetChannelString(channel, "steam_new_comment", map[string]string{"CHANNEL": channel})
 	b.SendMessage(channel, msg)
 }
 
@@ -321,14 +503,40 @@ func (b *Bot) startRandomKiller(userMsg db.Message) {
 		return
 	}
 
-	nextKiller := selectKillerWeighted(killerList, userMsg.Channel)
+	if !b.CategoryAllowed(userMsg.Channel) {
+		slog.Debug("Failed to start random killer",
+			slog.String("channel", userMsg.Channel),
+			slog.String("cause", "category is not allowed"),
+			slog.String("category", b.GetCachedCategory(userMsg.Channel)),
+		)
+		return
+	}
+
+	if !b.InWindow(userMsg.Channel, time.Now()) {
+		slog.Debug("Failed to start random killer",
+			slog.String("channel", userMsg.Channel),
+			slog.String("cause", "outside of killer windows"),
+		)
+		return
+	}
+
+	nextKiller := b.Pick(userMsg.Channel)
+	if nextKiller == nil {
+		slog.Debug("Failed to start random killer",
+			slog.String("channel", userMsg.Channel),
+			slog.String("cause", "missing moderator status or token scopes"),
+		)
+		return
+	}
 
 	slog.Debug("Starting killer",
 		slog.String("channel", userMsg.Channel),
 		slog.String("name", nextKiller.Name()),
 	)
 
+	b.prepareSession(userMsg.Channel, nextKiller.Name())
 	nextKiller.Start(userMsg)
+	b.beginSession(userMsg.Channel, nextKiller.Name())
 }
 
 func (b *Bot) StartSpecificKiller(channel, name string) error {
@@ -351,36 +559,23 @@ func (b *Bot) StartSpecificKiller(channel, name string) error {
 		return fmt.Errorf("killer not found")
 	}
 
+	if err := b.Check(channel, nextKiller); err != nil {
+		return err
+	}
+
 	slog.Debug("Starting killer",
 		slog.String("channel", channel),
 		slog.String("name", name),
 	)
 
+	b.prepareSession(channel, name)
 	nextKiller.Start(db.Message{
 		Channel:  chanState.Channel,
 		Username: util.BotUsername,
 		IsMod:    false,
 		Text:     "",
 	})
+	b.beginSession(channel, name)
 
 	return nil
 }
-
-func selectKillerWeighted(arr []killer.Killer, channel string) killer.Killer {
-	totalWeight := 0
-	for _, k := range arr {
-		totalWeight += k.Weight(channel)
-	}
-
-	r := rand.Intn(totalWeight)
-
-	runningTotal := 0
-	for _, k := range arr {
-		runningTotal += k.Weight(channel)
-		if r < runningTotal {
-			return k
-		}
-	}
-
-	return arr[0]
-}
@@ -0,0 +1,129 @@
+package budget
+
+import (
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// minTimeout is the shortest timeout worth issuing, shorter ones are only announced
+const minTimeout = 5 * time.Second
+
+var _ chat.Actions = (*Actions)(nil)
+
+// Actions charges every timeout to the moderation budget of the channel.
+// Repeated timeouts of the same viewer get shorter, timeouts are cut down to the remaining budget
+// and once it is spent the killers only announce their punishments.
+type Actions struct {
+	chat.Actions
+	database  db.DB
+	localiser i18n.Localiser
+}
+
+func New(actions chat.Actions, database db.DB, localiser i18n.Localiser) *Actions {
+	return &Actions{
+		Actions:   actions,
+		database:  database,
+		localiser: localiser,
+	}
+}
+
+func (a *Actions) TimeoutUser(channel, username string, duration time.Duration, reason string) {
+	settings := a.database.GetState(channel).Settings.Budget
+	if settings == nil || !settings.Enabled {
+		a.Actions.TimeoutUser(channel, username, duration, reason)
+		return
+	}
+
+	var allowed time.Duration
+	a.database.UpdateState(channel, func(chanState *db.ChannelState) {
+		allowed = charge(chanState, settings, username, duration)
+	})
+
+	if allowed == 0 {
+		slog.Info("Moderation budget exhausted, timeout skipped",
+			slog.String("channel", channel),
+			slog.String("username", username),
+			slog.Duration("duration", duration),
+		)
+
+		msg := a.localiser.GetChannelString(channel, "budget_timeout_skipped", map[string]string{"USERNAME": username})
+		a.SendMessage(channel, msg)
+		return
+	}
+
+	if allowed < duration {
+		slog.Info("Timeout shortened by the moderation budget",
+			slog.String("channel", channel),
+			slog.String("username", username),
+			slog.Duration("duration", duration),
+			slog.Duration("allowed", allowed),
+		)
+	}
+
+	a.Actions.TimeoutUser(channel, username, allowed, reason)
+}
+
+// charge books the timeout in the budget and returns its allowed duration, zero if it must be skipped
+func charge(chanState *db.ChannelState, settings *db.BudgetSettings, username string, duration time.Duration) time.Duration {
+	// safe mode only pretends to time out, so it must not spend the real budget
+	if chanState.Settings.SafeMode {
+		return duration
+	}
+
+	state := &chanState.Budget
+
+	if !state.SessionStart.Equal(chanState.Session.Start) {
+		state.SessionStart = chanState.Session.Start
+		state.SessionUsers = nil
+	}
+
+	user := state.Users[username]
+	if user == nil {
+		user = &db.UserBudget{}
+	}
+
+	// escalation: every further timeout of the viewer in this stream is scaled once more
+	allowed := duration
+	if settings.RepeatFactor > 0 {
+		for range user.Timeouts {
+			allowed = time.Duration(float64(allowed) * settings.RepeatFactor)
+		}
+	}
+
+	if settings.StreamTimeout > 0 {
+		allowed = min(allowed, settings.StreamTimeout-state.Spent)
+	}
+
+	if settings.UserTimeout > 0 {
+		allowed = min(allowed, settings.UserTimeout-user.Spent)
+	}
+
+	newInSession := !slices.Contains(state.SessionUsers, username)
+	if newInSession && settings.SessionUsers > 0 && len(state.SessionUsers) >= settings.SessionUsers {
+		allowed = 0
+	}
+
+	if allowed < minTimeout {
+		state.Skipped++
+		return 0
+	}
+
+	if state.Users == nil {
+		state.Users = make(map[string]*db.UserBudget)
+	}
+
+	state.Spent += allowed
+	user.Spent += allowed
+	user.Timeouts++
+	state.Users[username] = user
+
+	if newInSession {
+		state.SessionUsers = append(state.SessionUsers, username)
+	}
+
+	return allowed
+}
@@ -0,0 +1,119 @@
+package budget
+
+import (
+	"github.com/stretchr/testify/require"
+	"legion-bot-v2/db"
+	"testing"
+	"time"
+)
+
+func TestCharge(t *testing.T) {
+	firstSession := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+	secondSession := firstSession.Add(time.Hour)
+
+	type timeout struct {
+		username string
+		session  time.Time
+		duration time.Duration
+		allowed  time.Duration
+	}
+
+	tests := []struct {
+		name     string
+		settings db.BudgetSettings
+		safeMode bool
+		timeouts []timeout
+		skipped  int
+	}{
+		{
+			name:     "repeat timeouts get shorter",
+			settings: db.BudgetSettings{RepeatFactor: 0.5},
+			timeouts: []timeout{
+				{"alice", firstSession, time.Minute, time.Minute},
+				{"alice", firstSession, time.Minute, 30 * time.Second},
+				{"alice", firstSession, time.Minute, 15 * time.Second},
+				{"bob", firstSession, time.Minute, time.Minute},
+			},
+		},
+		{
+			name:     "user cap cuts a timeout short",
+			settings: db.BudgetSettings{UserTimeout: 90 * time.Second},
+			timeouts: []timeout{
+				{"alice", firstSession, time.Minute, time.Minute},
+				{"alice", firstSession, time.Minute, 30 * time.Second},
+				{"alice", firstSession, time.Minute, 0},
+			},
+			skipped: 1,
+		},
+		{
+			name:     "stream cap cuts a timeout short",
+			settings: db.BudgetSettings{StreamTimeout: 100 * time.Second},
+			timeouts: []timeout{
+				{"alice", firstSession, time.Minute, time.Minute},
+				{"bob", firstSession, time.Minute, 40 * time.Second},
+				{"carol", firstSession, time.Minute, 0},
+			},
+			skipped: 1,
+		},
+		{
+			name:     "too short remainder is skipped",
+			settings: db.BudgetSettings{StreamTimeout: 63 * time.Second},
+			timeouts: []timeout{
+				{"alice", firstSession, time.Minute, time.Minute},
+				{"bob", firstSession, time.Minute, 0},
+			},
+			skipped: 1,
+		},
+		{
+			name:     "distinct users per session",
+			settings: db.BudgetSettings{SessionUsers: 2},
+			timeouts: []timeout{
+				{"alice", firstSession, time.Minute, time.Minute},
+				{"bob", firstSession, time.Minute, time.Minute},
+				{"carol", firstSession, time.Minute, 0},
+				{"alice", firstSession, time.Minute, time.Minute},
+			},
+			skipped: 1,
+		},
+		{
+			name:     "new session resets distinct users",
+			settings: db.BudgetSettings{SessionUsers: 1},
+			timeouts: []timeout{
+				{"alice", firstSession, time.Minute, time.Minute},
+				{"bob", firstSession, time.Minute, 0},
+				{"bob", secondSession, time.Minute, time.Minute},
+				{"alice", secondSession, time.Minute, 0},
+			},
+			skipped: 2,
+		},
+		{
+			name:     "safe mode doesn't spend the budget",
+			settings: db.BudgetSettings{StreamTimeout: 90 * time.Second, SessionUsers: 1},
+			safeMode: true,
+			timeouts: []timeout{
+				{"alice", firstSession, time.Minute, time.Minute},
+				{"bob", firstSession, time.Minute, time.Minute},
+				{"carol", firstSession, time.Minute, time.Minute},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chanState db.ChannelState
+			chanState.Settings.SafeMode = tt.safeMode
+
+			for i, timeout := range tt.timeouts {
+				chanState.Session.Start = timeout.session
+
+				allowed := charge(&chanState, &tt.settings, timeout.username, timeout.duration)
+				require.Equal(t, timeout.allowed, allowed, "timeout %d of %s", i, timeout.username)
+			}
+
+			require.Equal(t, tt.skipped, chanState.Budget.Skipped)
+			if tt.safeMode {
+				require.Zero(t, chanState.Budget.Spent)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package bot
 
 import (
 	"fmt"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/bot/punishments"
 	"legion-bot-v2/db"
 	"legion-bot-v2/util"
 	"log/slog"
@@ -9,209 +12,331 @@ import (
 	"time"
 )
 
+func (b *Bot) registerCommands() {
+	b.RegisterCommands(commands.GlobalChannel, "bot",
+		commands.Command{
+			Name:           "legionbot",
+			Aliases:        []string{"легионбот"},
+			GlobalCooldown: 15 * time.Second,
+			Handler:        b.handleLegionBotCommand,
+		},
+		commands.Command{
+			Name:           "killer",
+			Aliases:        []string{"убийца"},
+			GlobalCooldown: 5 * time.Second,
+			Handler:        b.handleKillerCommand,
+		},
+		commands.Command{
+			Name:       "legiontimeout",
+			Permission: commands.PermissionBroadcaster,
+			Handler:    b.handleTimeoutCommand,
+		},
+		commands.Command{
+			Name:         "hp",
+			Aliases:      []string{"хп", "health"},
+			UserCooldown: 5 * time.Second,
+			Handler:      b.handleHealthCommand,
+		},
+		commands.Command{
+			Name:         "unhook",
+			Aliases:      []string{"анхук", "снять"},
+			UserCooldown: 3 * time.Second,
+			Handler:      b.handleUnhookCommand,
+		},
+		commands.Command{
+			Name:         "heal",
+			Aliases:      []string{"хил", "лечить"},
+			UserCooldown: 3 * time.Second,
+			Handler:      b.handleHealCommand,
+		},
+		commands.Command{
+			Name:         "mend",
+			Aliases:      []string{"менд"},
+			UserCooldown: 3 * time.Second,
+			Handler:      b.handleMendCommand,
+		},
+		commands.Command{
+			Name:         "bp",
+			Aliases:      []string{"бп", "bloodpoints"},
+			UserCooldown: 10 * time.Second,
+			Handler:      b.handleBalanceCommand,
+		},
+		commands.Command{
+			Name:           "shop",
+			Aliases:        []string{"магазин"},
+			GlobalCooldown: 10 * time.Second,
+			Handler:        b.handleShopCommand,
+		},
+		commands.Command{
+			Name:         "buy",
+			Aliases:      []string{"купить"},
+			UserCooldown: 3 * time.Second,
+			Handler:      b.handleBuyCommand,
+		},
+		commands.Command{
+			Name:         "rituals",
+			Aliases:      []string{"ритуалы"},
+			UserCooldown: 10 * time.Second,
+			Handler:      b.handleRitualsCommand,
+		},
+		commands.Command{
+			Name:           "queue",
+			Aliases:        []string{"очередь"},
+			GlobalCooldown: 5 * time.Second,
+			Handler:        b.handleQueueCommand,
+		},
+		commands.Command{
+			Name:         "lang",
+			Aliases:      []string{"язык", "language"},
+			UserCooldown: 5 * time.Second,
+			Handler:      b.handleLangCommand,
+		},
+		commands.Command{
+			Name:           "clip",
+			Aliases:        []string{"клип"},
+			GlobalCooldown: 30 * time.Second,
+			UserCooldown:   2 * time.Minute,
+			Handler:        b.handleClipCommand,
+		},
+	)
+}
+
 func (b *Bot) HandleCommands(userMsg db.Message) bool {
-	chanState := b.GetState(userMsg.Channel)
-	lang := chanState.Settings.Language
-	user := chanState.UserMap[userMsg.Username]
+	lang := b.UserLanguage(userMsg.Channel, userMsg.Username)
 
-	switch {
-	case strings.HasPrefix(userMsg.Text, "!legiontimeout") && userMsg.Username == userMsg.Channel:
-		timeStr := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(userMsg.Text, "!legiontimeout")))
+	if b.DispatchCommand(userMsg) {
+		return true
+	}
 
-		duration, err := time.ParseDuration(timeStr)
+	if strings.Contains(userMsg.Text, util.BotUsername) ||
+		strings.Contains(userMsg.Text, "легион") ||
+		strings.Contains(userMsg.Text, "лиджн") ||
+		strings.Contains(userMsg.Text, "лиджен") ||
+		strings.Contains(userMsg.Text, "legion") {
+		responseText, err := b.GenericResponse(lang, userMsg.Text)
 		if err != nil {
-			b.SendMessage(userMsg.Channel, fmt.Sprintf("Error: %v", err))
+			slog.Error("Failed to generate a generic response",
+				slog.String("user", userMsg.Username),
+				slog.String("text", userMsg.Text),
+				slog.Any("error", err),
+			)
 			return true
 		}
 
-		timeoutTime := time.Now().Add(duration)
+		b.SendMessage(userMsg.Channel, "@"+userMsg.Username+" "+responseText)
 
-		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-			chanState.UserTimeout = timeoutTime
-		})
+		return true
+	}
+
+	return false
+}
 
-		b.SendMessage(userMsg.Channel, fmt.Sprintf("Timeout till %v", timeoutTime.String()))
+// reply answers the author of a command, as a threaded reply unless the channel prefers plain messages
+func (b *Bot) reply(userMsg db.Message, text string) {
+	chanState := b.GetState(userMsg.Channel)
+	b.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), text)
+}
 
-		b.UpdateState(userMsg.Channel, func(state *db.ChannelState) {
-			if state.Killer != "" {
-				state.Killer = ""
-				state.KillerState = nil
-				state.Date = time.Now()
-			}
+func (b *Bot) handleTimeoutCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
 
-			b.StopChannelTimers(userMsg.Channel)
-		})
+	timeStr := strings.ToLower(call.Rest())
 
+	duration, err := time.ParseDuration(timeStr)
+	if err != nil {
+		b.SendMessage(userMsg.Channel, fmt.Sprintf("Error: %v", err))
 		return true
+	}
 
-	case strings.HasPrefix(userMsg.Text, "!hp"):
-		otherUsername := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.ReplaceAll(userMsg.Text, "@", ""), "!hp")))
-		if otherUsername == "" {
-			otherUsername = userMsg.Username
-		}
+	timeoutTime := time.Now().Add(duration)
 
-		otherUser, userExists := chanState.UserMap[otherUsername]
-		if !userExists {
-			otherUser = db.NewUser()
-			b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-				chanState.UserMap[otherUsername] = otherUser
-			})
-		}
+	b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+		chanState.UserTimeout = timeoutTime
+	})
 
-		var msg string
-		switch otherUser.Health {
-		case "hooked":
-			msg = b.GetLocalString(lang, "hooked", map[string]string{"USERNAME": otherUsername})
-		case "deep_wound":
-			msg = b.GetLocalString(lang, "deep_wound", map[string]string{"USERNAME": otherUsername})
-		case "injured":
-			msg = b.GetLocalString(lang, "injured", map[string]string{"USERNAME": otherUsername})
-		case "dead":
-			msg = b.GetLocalString(lang, "dead", map[string]string{"USERNAME": otherUsername})
-		case "healthy":
-			msg = b.GetLocalString(lang, "healthy", map[string]string{"USERNAME": otherUsername})
-		default:
-			return true
-		}
+	b.SendMessage(userMsg.Channel, fmt.Sprintf("Timeout till %v", timeoutTime.String()))
 
-		b.SendMessage(userMsg.Channel, msg)
+	b.UpdateState(userMsg.Channel, func(state *db.ChannelState) {
+		if state.Killer != "" {
+			state.Killer = ""
+			state.KillerState = nil
+			state.Date = time.Now()
+		}
 
-		return true
+		b.StopChannelTimers(userMsg.Channel)
+	})
 
-	case strings.HasPrefix(userMsg.Text, "!unhook"):
-		otherUsername := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.ReplaceAll(userMsg.Text, "@", ""), "!unhook")))
-		if otherUsername == "" {
-			otherUsername = userMsg.Username
-		}
+	if chanState.Killer != "" {
+		b.Emit(events.Event{Channel: userMsg.Channel, Type: events.TypeSessionEnd, Killer: chanState.Killer})
+	}
 
-		otherUser, userExists := chanState.UserMap[otherUsername]
-		if !userExists {
-			otherUser = db.NewUser()
-			b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-				chanState.UserMap[otherUsername] = otherUser
-			})
-		}
+	b.Reconcile(userMsg.Channel, punishments.ReasonPause)
 
-		if otherUsername == userMsg.Username {
-			msg := b.GetLocalString(lang, "cant_unhook_self", map[string]string{"USERNAME": otherUsername})
-			b.SendMessage(userMsg.Channel, msg)
+	return true
+}
 
-			return true
-		}
+func (b *Bot) handleClipCommand(call commands.Call) bool {
+	b.Clip(call.Message.Channel, call.Message.Username)
+	return true
+}
 
-		if otherUser.Health != "hooked" {
-			msg := b.GetLocalString(lang, "not_hooked", map[string]string{"USERNAME": otherUsername})
-			b.SendMessage(userMsg.Channel, msg)
+func (b *Bot) handleHealthCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
 
-			return true
-		}
+	otherUsername := call.Target()
 
+	otherUser, userExists := chanState.UserMap[otherUsername]
+	if !userExists {
+		otherUser = db.NewUser()
 		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-			chanState.UserMap[otherUsername].Health = "healthy"
+			chanState.UserMap[otherUsername] = otherUser
+		})
+	}
+
+	var msg string
+	switch otherUser.Health {
+	case "hooked":
+		msg = b.GetUserString(userMsg.Channel, userMsg.Username, "hooked", map[string]string{"USERNAME": otherUsername})
+	case "deep_wound":
+		msg = b.GetUserString(userMsg.Channel, userMsg.Username, "deep_wound", map[string]string{"USERNAME": otherUsername})
+	case "injured":
+		msg = b.GetUserString(userMsg.Channel, userMsg.Username, "injured", map[string]string{"USERNAME": otherUsername})
+	case "dead":
+		msg = b.GetUserString(userMsg.Channel, userMsg.Username, "dead", map[string]string{"USERNAME": otherUsername})
+	case "healthy":
+		msg = b.GetUserString(userMsg.Channel, userMsg.Username, "healthy", map[string]string{"USERNAME": otherUsername})
+	default:
+		return true
+	}
 
-			chanState.UserMap[userMsg.Username].Stats["unhooks"]++
+	b.reply(userMsg, msg)
+
+	return true
+}
+
+func (b *Bot) handleUnhookCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
+
+	otherUsername := call.Target()
+
+	otherUser, userExists := chanState.UserMap[otherUsername]
+	if !userExists {
+		otherUser = db.NewUser()
+		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+			chanState.UserMap[otherUsername] = otherUser
 		})
+	}
 
-		b.UnbanUser(userMsg.Channel, otherUsername)
-		b.StopTimer(userMsg.Channel, otherUsername)
+	if otherUsername == userMsg.Username {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "cant_unhook_self", map[string]string{"USERNAME": otherUsername})
+		b.reply(userMsg, msg)
 
-		msg := b.GetLocalString(lang, "on_unhooked", map[string]string{"USERNAME": otherUsername})
-		b.SendMessage(userMsg.Channel, msg)
+		return true
+	}
+
+	if otherUser.Health != "hooked" {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "not_hooked", map[string]string{"USERNAME": otherUsername})
+		b.reply(userMsg, msg)
 
 		return true
+	}
 
-	case strings.HasPrefix(userMsg.Text, "!heal"):
-		otherUsername := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.ReplaceAll(userMsg.Text, "@", ""), "!heal")))
-		if otherUsername == "" {
-			otherUsername = userMsg.Username
-		}
+	b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+		chanState.UserMap[otherUsername].Health = "healthy"
 
-		otherUser, userExists := chanState.UserMap[otherUsername]
-		if !userExists {
-			otherUser = db.NewUser()
-			b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-				chanState.UserMap[otherUsername] = otherUser
-			})
-		}
+		chanState.UserMap[userMsg.Username].Stats["unhooks"]++
+	})
 
-		if otherUsername == userMsg.Username {
-			msg := b.GetLocalString(lang, "cant_heal_self", map[string]string{"USERNAME": otherUsername})
-			b.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+	b.UnbanUser(userMsg.Channel, otherUsername)
+	b.StopTimer(userMsg.Channel, otherUsername)
+	b.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeUnhook, Killer: chanState.Killer})
 
-		if user.Health == "hooked" || user.Health == "dead" {
-			msg := b.GetLocalString(lang, "cant_do_rn", map[string]string{"USERNAME": otherUsername})
-			b.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+	msg := b.GetChannelString(userMsg.Channel, "on_unhooked", map[string]string{"USERNAME": otherUsername})
+	b.SendMessage(userMsg.Channel, msg)
 
-		if otherUser.Health == "hooked" {
-			msg := b.GetLocalString(lang, "hooked", map[string]string{"USERNAME": otherUsername})
-			b.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+	return true
+}
 
-		if otherUser.Health == "healthy" {
-			msg := b.GetLocalString(lang, "healthy", map[string]string{"USERNAME": otherUsername})
-			b.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+func (b *Bot) handleHealCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
+	user := chanState.UserMap[userMsg.Username]
 
-		if otherUser.Health == "dead" {
-			b.UnbanUser(userMsg.Channel, otherUsername)
-		}
+	otherUsername := call.Target()
 
+	otherUser, userExists := chanState.UserMap[otherUsername]
+	if !userExists {
+		otherUser = db.NewUser()
 		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-			chanState.UserMap[otherUsername].Health = "healthy"
-			chanState.UserMap[userMsg.Username].Stats["heals"]++
+			chanState.UserMap[otherUsername] = otherUser
 		})
+	}
 
-		b.StopTimer(userMsg.Channel, otherUsername)
+	if otherUsername == userMsg.Username {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "cant_heal_self", map[string]string{"USERNAME": otherUsername})
+		b.reply(userMsg, msg)
+		return true
+	}
 
-		msg := b.GetLocalString(lang, "on_heal", map[string]string{"USERNAME": otherUsername})
-		b.SendMessage(userMsg.Channel, msg)
+	if user.Health == "hooked" || user.Health == "dead" {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "cant_do_rn", map[string]string{"USERNAME": otherUsername})
+		b.reply(userMsg, msg)
+		return true
+	}
 
+	if otherUser.Health == "hooked" {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "hooked", map[string]string{"USERNAME": otherUsername})
+		b.reply(userMsg, msg)
 		return true
+	}
 
-	case strings.HasPrefix(userMsg.Text, "!mend"):
-		if user.Health != "deep_wound" {
-			msg := b.GetLocalString(lang, "not_deep_wound", map[string]string{"USERNAME": userMsg.Username})
-			b.SendMessage(userMsg.Channel, msg)
+	if otherUser.Health == "healthy" {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "healthy", map[string]string{"USERNAME": otherUsername})
+		b.reply(userMsg, msg)
+		return true
+	}
 
-			return true
-		}
+	if otherUser.Health == "dead" {
+		b.UnbanUser(userMsg.Channel, otherUsername)
+	}
 
-		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-			chanState.UserMap[userMsg.Username].Health = "injured"
-		})
+	b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+		chanState.UserMap[otherUsername].Health = "healthy"
+		chanState.UserMap[userMsg.Username].Stats["heals"]++
+	})
 
-		b.StopTimer(userMsg.Channel, userMsg.Username)
+	b.StopTimer(userMsg.Channel, otherUsername)
+	b.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeHeal, Killer: chanState.Killer})
 
-		msg := b.GetLocalString(lang, "on_mend", map[string]string{"USERNAME": userMsg.Username})
-		b.SendMessage(userMsg.Channel, msg)
+	msg := b.GetChannelString(userMsg.Channel, "on_heal", map[string]string{"USERNAME": otherUsername})
+	b.SendMessage(userMsg.Channel, msg)
 
-		return true
+	return true
+}
 
-	case strings.Contains(userMsg.Text, util.BotUsername) ||
-		strings.Contains(userMsg.Text, "легион") ||
-		strings.Contains(userMsg.Text, "лиджн") ||
-		strings.Contains(userMsg.Text, "лиджен") ||
-		strings.Contains(userMsg.Text, "legion"):
-		responseText, err := b.GenericResponse(lang, userMsg.Text)
-		if err != nil {
-			slog.Error("Failed to generate a generic response",
-				slog.String("user", userMsg.Username),
-				slog.String("text", userMsg.Text),
-				slog.Any("error", err),
-			)
-			return true
-		}
+func (b *Bot) handleMendCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
+	user := chanState.UserMap[userMsg.Username]
 
-		b.SendMessage(userMsg.Channel, "@"+userMsg.Username+" "+responseText)
+	if user.Health != "deep_wound" {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "not_deep_wound", map[string]string{"USERNAME": userMsg.Username})
+		b.reply(userMsg, msg)
 
 		return true
 	}
 
-	return false
+	b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+		chanState.UserMap[userMsg.Username].Health = "injured"
+	})
+
+	b.StopTimer(userMsg.Channel, userMsg.Username)
+
+	msg := b.GetChannelString(userMsg.Channel, "on_mend", map[string]string{"USERNAME": userMsg.Username})
+	b.SendMessage(userMsg.Channel, msg)
+
+	return true
 }
@@ -0,0 +1,331 @@
+package commands
+
+import (
+	"github.com/samber/do"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/db"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GlobalChannel is the channel used to register commands that are available in every channel
+const GlobalChannel = ""
+
+type Permission string
+
+var (
+	PermissionEveryone    = Permission("everyone")
+	PermissionSubscriber  = Permission("subscriber")
+	PermissionVIP         = Permission("vip")
+	PermissionMod         = Permission("mod")
+	PermissionBroadcaster = Permission("broadcaster")
+)
+
+var permissionRanks = map[Permission]int{
+	PermissionEveryone:    0,
+	PermissionSubscriber:  1,
+	PermissionVIP:         2,
+	PermissionMod:         3,
+	PermissionBroadcaster: 4,
+}
+
+func (p Permission) Valid() bool {
+	_, ok := permissionRanks[p]
+	return ok || p == ""
+}
+
+// Allows reports whether the author of the message has at least this permission level.
+func (p Permission) Allows(userMsg db.Message) bool {
+	var rank int
+	switch {
+	case userMsg.Username == userMsg.Channel:
+		rank = permissionRanks[PermissionBroadcaster]
+	case userMsg.IsMod:
+		rank = permissionRanks[PermissionMod]
+	case userMsg.IsVIP:
+		rank = permissionRanks[PermissionVIP]
+	case userMsg.IsSubscriber:
+		rank = permissionRanks[PermissionSubscriber]
+	}
+
+	return rank >= permissionRanks[p]
+}
+
+// Call is a single parsed invocation of a command
+type Call struct {
+	Message db.Message
+	Name    string
+	Alias   string
+	Args    []string
+}
+
+// Target returns the user the command is aimed at, which is the first argument or the author.
+func (c Call) Target() string {
+	if len(c.Args) == 0 {
+		return c.Message.Username
+	}
+
+	target := strings.ToLower(strings.TrimPrefix(c.Args[0], "@"))
+	if target == "" {
+		return c.Message.Username
+	}
+
+	return target
+}
+
+// Rest returns all arguments joined by spaces.
+func (c Call) Rest() string {
+	return strings.Join(c.Args, " ")
+}
+
+// Handler handles a call and reports whether the message was consumed
+type Handler func(call Call) bool
+
+type Command struct {
+	Name           string
+	Aliases        []string
+	Permission     Permission
+	GlobalCooldown time.Duration
+	UserCooldown   time.Duration
+	Handler        Handler
+
+	// Override lets a custom command take precedence over built-in commands with the same name
+	Override bool
+	Owner    string
+}
+
+// NormalizeName turns a user supplied command name like "!Heal" into its registry form.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "!"))
+}
+
+// Parse splits a chat message into a lowercase command name and its arguments.
+func Parse(text string) (string, []string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || len(fields[0]) < 2 || !strings.HasPrefix(fields[0], "!") {
+		return "", nil, false
+	}
+
+	return strings.ToLower(strings.TrimPrefix(fields[0], "!")), fields[1:], true
+}
+
+type Registry interface {
+	RegisterCommands(channel, owner string, commands ...Command)
+	UnregisterCommands(channel, owner string)
+	SetCustomCommands(channel string, commands []Command)
+	LookupCommand(channel, name string) (Command, bool)
+	ListCommands(channel string) []Command
+	DispatchCommand(userMsg db.Message) bool
+}
+
+var _ Registry = (*Impl)(nil)
+
+type Impl struct {
+	db.DB
+
+	mutex     sync.Mutex
+	commands  map[string]map[string]Command
+	aliases   map[string]map[string]string
+	custom    map[string]map[string]Command
+	cooldowns map[string]time.Time
+}
+
+func New(di *do.Injector) Registry {
+	registry := &Impl{
+		DB:        do.MustInvoke[db.DB](di),
+		commands:  make(map[string]map[string]Command),
+		aliases:   make(map[string]map[string]string),
+		custom:    make(map[string]map[string]Command),
+		cooldowns: make(map[string]time.Time),
+	}
+
+	do.MustInvoke[events.Bus](di).Subscribe(registry.handleEvent)
+
+	return registry
+}
+
+// handleEvent removes the commands of a killer once its session is over
+func (r *Impl) handleEvent(event events.Event) {
+	if event.Type == events.TypeSessionEnd {
+		r.UnregisterCommands(event.Channel, event.Killer)
+	}
+}
+
+func (r *Impl) RegisterCommands(channel, owner string, commands ...Command) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.commands[channel] == nil {
+		r.commands[channel] = make(map[string]Command)
+		r.aliases[channel] = make(map[string]string)
+	}
+
+	for _, command := range commands {
+		command.Owner = owner
+		if command.Permission == "" {
+			command.Permission = PermissionEveryone
+		}
+
+		r.commands[channel][command.Name] = command
+		r.aliases[channel][command.Name] = command.Name
+		for _, alias := range command.Aliases {
+			r.aliases[channel][alias] = command.Name
+		}
+	}
+}
+
+func (r *Impl) UnregisterCommands(channel, owner string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for name, command := range r.commands[channel] {
+		if command.Owner != owner {
+			continue
+		}
+
+		delete(r.commands[channel], name)
+		for alias, aliasName := range r.aliases[channel] {
+			if aliasName == name {
+				delete(r.aliases[channel], alias)
+			}
+		}
+	}
+}
+
+// SetCustomCommands replaces the custom commands of a channel.
+// Custom commands are only used when no built-in command matches, unless they are marked with Override.
+func (r *Impl) SetCustomCommands(channel string, commands []Command) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	custom := make(map[string]Command)
+	for _, command := range commands {
+		command.Owner = "custom"
+		if command.Permission == "" {
+			command.Permission = PermissionEveryone
+		}
+
+		for _, alias := range command.Aliases {
+			custom[alias] = command
+		}
+		custom[command.Name] = command
+	}
+
+	r.custom[channel] = custom
+}
+
+// LookupCommand resolves a name or an alias, channel commands take precedence over global ones.
+func (r *Impl) LookupCommand(channel, name string) (Command, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.lookup(channel, name)
+}
+
+func (r *Impl) lookup(channel, name string) (Command, bool) {
+	custom, isCustom := r.custom[channel][name]
+	if isCustom && custom.Override {
+		return custom, true
+	}
+
+	for _, c := range []string{channel, GlobalChannel} {
+		if canonical, ok := r.aliases[c][name]; ok {
+			return r.commands[c][canonical], true
+		}
+	}
+
+	return custom, isCustom
+}
+
+func (r *Impl) ListCommands(channel string) []Command {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	byName := make(map[string]Command)
+	for _, command := range r.custom[channel] {
+		byName[command.Name] = command
+	}
+	for _, c := range []string{GlobalChannel, channel} {
+		for name, command := range r.commands[c] {
+			if custom, ok := byName[name]; ok && custom.Override {
+				continue
+			}
+			byName[name] = command
+		}
+	}
+
+	result := make([]Command, 0, len(byName))
+	for _, command := range byName {
+		result = append(result, command)
+	}
+
+	slices.SortFunc(result, func(a, b Command) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return result
+}
+
+func (r *Impl) DispatchCommand(userMsg db.Message) bool {
+	name, args, ok := Parse(userMsg.Text)
+	if !ok {
+		return false
+	}
+
+	chanState := r.GetState(userMsg.Channel)
+
+	r.mutex.Lock()
+
+	command, ok := r.lookup(userMsg.Channel, name)
+	if !ok || chanState.Settings.Commands != nil && slices.Contains(chanState.Settings.Commands.Disabled, command.Name) {
+		r.mutex.Unlock()
+		return false
+	}
+
+	if !command.Permission.Allows(userMsg) {
+		r.mutex.Unlock()
+		return false
+	}
+
+	now := time.Now()
+	globalKey := userMsg.Channel + "/" + command.Name
+	userKey := globalKey + "/" + userMsg.Username
+
+	if now.Before(r.cooldowns[globalKey]) || now.Before(r.cooldowns[userKey]) {
+		r.mutex.Unlock()
+		return true
+	}
+
+	if command.GlobalCooldown > 0 {
+		r.cooldowns[globalKey] = now.Add(command.GlobalCooldown)
+	}
+	if command.UserCooldown > 0 {
+		r.cooldowns[userKey] = now.Add(command.UserCooldown)
+	}
+
+	r.pruneCooldowns(now)
+
+	r.mutex.Unlock()
+
+	return command.Handler(Call{
+		Message: userMsg,
+		Name:    command.Name,
+		Alias:   name,
+		Args:    args,
+	})
+}
+
+// pruneCooldowns drops expired cooldowns so that the map doesn't grow with every chatter
+func (r *Impl) pruneCooldowns(now time.Time) {
+	if len(r.cooldowns) < 1000 {
+		return
+	}
+
+	for key, until := range r.cooldowns {
+		if now.After(until) {
+			delete(r.cooldowns, key)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package bot
+
+import (
+	"fmt"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/killer/custom"
+	"legion-bot-v2/db"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	MaxCustomCommands         = 50
+	MaxCustomCommandAliases   = 5
+	MaxCustomCommandResponse  = 400
+	MaxCustomCommandCooldown  = time.Hour
+	customCommandUserCooldown = 3 * time.Second
+)
+
+var (
+	customCommandNameRegex = regexp.MustCompile(`^[\p{L}\p{N}_]{1,24}$`)
+	templateVariableRegex  = regexp.MustCompile(`\{([a-z_]+(?:\.[a-zA-Z_]+)?)}`)
+)
+
+// SyncCustomCommands makes the registry serve the custom commands currently stored in the channel settings.
+func (b *Bot) SyncCustomCommands(channel string) {
+	chanState := b.GetState(channel)
+	if chanState.Settings.Commands == nil {
+		b.SetCustomCommands(channel, nil)
+		return
+	}
+
+	var result []commands.Command
+	for _, customCommand := range chanState.Settings.Commands.Custom {
+		response := customCommand.Response
+
+		result = append(result, commands.Command{
+			Name:           customCommand.Name,
+			Aliases:        customCommand.Aliases,
+			Permission:     commands.Permission(customCommand.Permission),
+			GlobalCooldown: customCommand.GlobalCooldown,
+			UserCooldown:   max(customCommand.UserCooldown, customCommandUserCooldown),
+			Override:       customCommand.Override,
+			Handler: func(call commands.Call) bool {
+				b.SendMessage(call.Message.Channel, b.renderTemplate(response, call))
+				return true
+			},
+		})
+	}
+
+	b.SetCustomCommands(channel, result)
+}
+
+// ValidateCustomCommands normalizes the custom commands of a channel and checks them against the limits.
+// A custom command may only reuse the name of a built-in command if it explicitly overrides it.
+func (b *Bot) ValidateCustomCommands(customCommands []db.CustomCommand) ([]db.CustomCommand, error) {
+	if len(customCommands) > MaxCustomCommands {
+		return nil, fmt.Errorf("at most %d custom commands are allowed", MaxCustomCommands)
+	}
+
+	builtinNames := b.builtinCommandNames()
+	usedNames := make(map[string]bool)
+
+	result := make([]db.CustomCommand, 0, len(customCommands))
+	for _, customCommand := range customCommands {
+		customCommand.Name = commands.NormalizeName(customCommand.Name)
+		for i, alias := range customCommand.Aliases {
+			customCommand.Aliases[i] = commands.NormalizeName(alias)
+		}
+
+		if customCommand.Permission == "" {
+			customCommand.Permission = string(commands.PermissionEveryone)
+		}
+
+		if !commands.Permission(customCommand.Permission).Valid() {
+			return nil, fmt.Errorf("!%s: unknown permission %q", customCommand.Name, customCommand.Permission)
+		}
+
+		if strings.TrimSpace(customCommand.Response) == "" || len([]rune(customCommand.Response)) > MaxCustomCommandResponse {
+			return nil, fmt.Errorf("!%s: response must be 1-%d characters long", customCommand.Name, MaxCustomCommandResponse)
+		}
+
+		if customCommand.GlobalCooldown < 0 || customCommand.GlobalCooldown > MaxCustomCommandCooldown ||
+			customCommand.UserCooldown < 0 || customCommand.UserCooldown > MaxCustomCommandCooldown {
+			return nil, fmt.Errorf("!%s: cooldowns must be between 0 and %s", customCommand.Name, MaxCustomCommandCooldown)
+		}
+
+		if len(customCommand.Aliases) > MaxCustomCommandAliases {
+			return nil, fmt.Errorf("!%s: at most %d aliases are allowed", customCommand.Name, MaxCustomCommandAliases)
+		}
+
+		for _, name := range append([]string{customCommand.Name}, customCommand.Aliases...) {
+			if !customCommandNameRegex.MatchString(name) {
+				return nil, fmt.Errorf("invalid command name %q", name)
+			}
+
+			if usedNames[name] {
+				return nil, fmt.Errorf("duplicate command name %q", name)
+			}
+			usedNames[name] = true
+
+			if builtinNames[name] && !customCommand.Override {
+				return nil, fmt.Errorf("!%s is a built-in command, set override to replace it", name)
+			}
+		}
+
+		result = append(result, customCommand)
+	}
+
+	return result, nil
+}
+
+// builtinCommandNames returns the names and aliases of the global commands and of all killer commands
+func (b *Bot) builtinCommandNames() map[string]bool {
+	builtin := b.ListCommands(commands.GlobalChannel)
+	for _, k := range b.killerMap {
+		builtin = append(builtin, k.Commands()...)
+	}
+
+	result := make(map[string]bool)
+	for _, command := range builtin {
+		result[command.Name] = true
+		for _, alias := range command.Aliases {
+			result[alias] = true
+		}
+	}
+
+	return result
+}
+
+// renderTemplate fills in the {variables} of a custom command response.
+// Only a fixed set of read-only variables is supported and unknown ones are left untouched.
+func (b *Bot) renderTemplate(text string, call commands.Call) string {
+	channel := call.Message.Channel
+	chanState := b.GetState(channel)
+	target := call.Target()
+
+	targetUser, ok := chanState.UserMap[target]
+	if !ok {
+		targetUser = db.NewUser()
+	}
+
+	result := templateVariableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		variable := templateVariableRegex.FindStringSubmatch(match)[1]
+		group, key, _ := strings.Cut(variable, ".")
+
+		switch group {
+		case "sender":
+			return call.Message.Username
+		case "target":
+			return target
+		case "channel":
+			return channel
+		case "args":
+			return call.Rest()
+		case "health":
+			return targetUser.Health
+		case "bp":
+			return fmt.Sprint(b.Balance(channel, target))
+		case "stats":
+			return fmt.Sprint(targetUser.Stats[key])
+		case "total":
+			return fmt.Sprint(chanState.Stats[key])
+		case "killer":
+			if chanState.Killer == "" {
+				return b.GetChannelString(channel, "killer_none", nil)
+			}
+			if def, _, ok := custom.Current(chanState); ok {
+				return def.DisplayName
+			}
+			return b.GetChannelString(channel, "killer_"+chanState.Killer, nil)
+		case "next_killer":
+			return b.nextKillerETA(channel)
+		}
+
+		return match
+	})
+
+	// chat treats messages starting with / or . as commands, which must not be reachable through arguments
+	return strings.TrimLeft(strings.TrimSpace(result), "/.")
+}
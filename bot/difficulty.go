@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"legion-bot-v2/db"
+	"math"
+	"sync"
+	"time"
+)
+
+type messageRate struct {
+	mutex sync.Mutex
+	times map[string][]time.Time
+}
+
+func newMessageRate() *messageRate {
+	return &messageRate{
+		times: make(map[string][]time.Time),
+	}
+}
+
+func (r *messageRate) trim(channel string, now time.Time) {
+	times := r.times[channel]
+
+	i := 0
+	for i < len(times) && now.Sub(times[i]) > time.Minute {
+		i++
+	}
+
+	r.times[channel] = times[i:]
+}
+
+func (r *messageRate) Record(channel string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	r.trim(channel, now)
+	r.times[channel] = append(r.times[channel], now)
+}
+
+func (r *messageRate) PerMinute(channel string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.trim(channel, time.Now())
+	return len(r.times[channel])
+}
+
+// Pressure estimates how crowded the chat is, 1 being the channel's reference chat.
+func (b *Bot) Pressure(channel string) float64 {
+	chanState := b.GetState(channel)
+	difficultySettings := chanState.Settings.Difficulty
+
+	if difficultySettings == nil {
+		return 1
+	}
+
+	var viewerPressure, messagePressure float64
+
+	if difficultySettings.ReferenceViewers > 0 {
+		viewerPressure = float64(b.GetCachedViewerCount(channel)) / float64(difficultySettings.ReferenceViewers)
+	}
+	if difficultySettings.ReferenceMessagesPerMinute > 0 {
+		messagePressure = float64(b.messageRate.PerMinute(channel)) / difficultySettings.ReferenceMessagesPerMinute
+	}
+
+	viewerWeight := min(max(difficultySettings.ViewerWeight, 0), 1)
+	pressure := viewerWeight*viewerPressure + (1-viewerWeight)*messagePressure
+
+	return min(max(pressure, difficultySettings.MinPressure), difficultySettings.MaxPressure)
+}
+
+func (b *Bot) difficultyModifiers(channel string) (float64, []db.SettingModifier) {
+	chanState := b.GetState(channel)
+	difficultySettings := chanState.Settings.Difficulty
+
+	if difficultySettings == nil || !difficultySettings.Enabled {
+		return 1, nil
+	}
+
+	pressure := b.Pressure(channel)
+	if pressure <= 0 {
+		return pressure, nil
+	}
+
+	var modifiers []db.SettingModifier
+	for _, curve := range difficultySettings.Curves {
+		modifiers = append(modifiers, db.SettingModifier{
+			Source:   "difficulty",
+			Field:    curve.Field,
+			Multiply: math.Pow(pressure, curve.Exponent),
+		})
+	}
+
+	return pressure, modifiers
+}
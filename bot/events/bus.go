@@ -0,0 +1,45 @@
+package events
+
+import (
+	"log/slog"
+	"sync"
+)
+
+var _ Bus = (*Dispatcher)(nil)
+
+// Dispatcher delivers events synchronously in the caller's goroutine,
+// so Emit must never be called from inside a db.UpdateState callback
+type Dispatcher struct {
+	mu        sync.RWMutex
+	listeners []Listener
+}
+
+func NewDispatcher() Bus {
+	return &Dispatcher{}
+}
+
+func (d *Dispatcher) Subscribe(listener Listener) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.listeners = append(d.listeners, listener)
+}
+
+func (d *Dispatcher) Emit(event Event) {
+	d.mu.RLock()
+	listeners := make([]Listener, len(d.listeners))
+	copy(listeners, d.listeners)
+	d.mu.RUnlock()
+
+	slog.Debug("Event",
+		slog.String("channel", event.Channel),
+		slog.String("username", event.Username),
+		slog.String("type", string(event.Type)),
+		slog.String("killer", event.Killer),
+		slog.String("detail", event.Detail),
+	)
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,31 @@
+package events
+
+type Type string
+
+var (
+	TypeSessionStart = Type("session_start")
+	TypeSessionEnd   = Type("session_end")
+	TypeHit          = Type("hit")
+	TypeHook         = Type("hook")
+	TypeHeal         = Type("heal")
+	TypeUnhook       = Type("unhook")
+	TypeStun         = Type("stun")
+	TypeReveal       = Type("reveal")
+	TypeSurvive      = Type("survive")
+	TypeTbag         = Type("tbag")
+)
+
+type Event struct {
+	Channel  string
+	Username string
+	Type     Type
+	Killer   string
+	Detail   string
+}
+
+type Listener func(event Event)
+
+type Bus interface {
+	Emit(event Event)
+	Subscribe(listener Listener)
+}
@@ -0,0 +1,177 @@
+package greetings
+
+import (
+	"fmt"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/bot/protection"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// batchWindow collects the chatters arriving together, e.g. during a raid, into a single line
+	batchWindow = 5 * time.Second
+	// minCooldown is the minimal delay between two greeting flushes of a channel
+	minCooldown = 10 * time.Second
+
+	maxNamesPerLine      = 10
+	maxMilestonesPerLine = 3
+)
+
+type Greeter interface {
+	// Greet records the activity of a chatter and queues a greeting if they deserve one.
+	// firstTime reports whether this is the first message of the user in the channel,
+	// streamStart is the start of the current stream or zero if the channel is offline.
+	Greet(userMsg db.Message, firstTime bool, streamStart time.Time)
+}
+
+var _ Greeter = (*Impl)(nil)
+
+type milestone struct {
+	username string
+	streams  int
+}
+
+type batch struct {
+	newcomers  []string
+	returning  []string
+	milestones []milestone
+}
+
+type Impl struct {
+	db.DB
+	chat.Actions
+	i18n.Localiser
+
+	mutex     sync.Mutex
+	pending   map[string]*batch
+	lastFlush map[string]time.Time
+}
+
+func New(di *do.Injector) Greeter {
+	return &Impl{
+		DB:        do.MustInvoke[db.DB](di),
+		Actions:   do.MustInvoke[chat.Actions](di),
+		Localiser: do.MustInvoke[i18n.Localiser](di),
+		pending:   make(map[string]*batch),
+		lastFlush: make(map[string]time.Time),
+	}
+}
+
+func (g *Impl) Greet(userMsg db.Message, firstTime bool, streamStart time.Time) {
+	channel := userMsg.Channel
+	username := userMsg.Username
+	settings := g.GetState(channel).Settings.Greetings
+	now := time.Now()
+
+	var returning bool
+	var streams int
+
+	// activity is tracked even when greetings are disabled, so that enabling them later works right away
+	g.UpdateState(channel, func(chanState *db.ChannelState) {
+		user := chanState.UserMap[username]
+		if user == nil {
+			return
+		}
+
+		if settings != nil && settings.ReturningAfter > 0 && !user.LastSeen.IsZero() {
+			returning = now.Sub(user.LastSeen) >= settings.ReturningAfter
+		}
+		user.LastSeen = now
+
+		if !streamStart.IsZero() && !user.LastStream.Equal(streamStart) {
+			user.LastStream = streamStart
+			user.Streams++
+			streams = user.Streams
+		}
+	})
+
+	if settings == nil || !settings.Enabled || protection.Spectator(channel, username) {
+		return
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	b, scheduled := g.pending[channel]
+	if !scheduled {
+		b = &batch{}
+	}
+
+	switch {
+	case firstTime && settings.NewChatters:
+		b.newcomers = append(b.newcomers, username)
+	case returning:
+		b.returning = append(b.returning, username)
+	case streams > 0 && slices.Contains(settings.Milestones, streams):
+		b.milestones = append(b.milestones, milestone{username: username, streams: streams})
+	default:
+		return
+	}
+
+	if scheduled {
+		return
+	}
+
+	g.pending[channel] = b
+
+	delay := max(batchWindow, g.lastFlush[channel].Add(max(settings.Cooldown, minCooldown)).Sub(now))
+	time.AfterFunc(delay, func() {
+		g.flush(channel)
+	})
+}
+
+func (g *Impl) flush(channel string) {
+	g.mutex.Lock()
+	b := g.pending[channel]
+	delete(g.pending, channel)
+	g.lastFlush[channel] = time.Now()
+	g.mutex.Unlock()
+
+	if b == nil {
+		return
+	}
+
+	slog.Info("Greeting chatters",
+		slog.String("channel", channel),
+		slog.Int("newcomers", len(b.newcomers)),
+		slog.Int("returning", len(b.returning)),
+		slog.Int("milestones", len(b.milestones)),
+	)
+
+	if len(b.newcomers) > 0 {
+		g.SendMessage(channel, g.namesLine(channel, "greet_new", b.newcomers))
+	}
+
+	if len(b.returning) > 0 {
+		g.SendMessage(channel, g.namesLine(channel, "greet_returning", b.returning))
+	}
+
+	for _, m := range b.milestones[:min(len(b.milestones), maxMilestonesPerLine)] {
+		msg := g.GetChannelString(channel, "greet_milestone", map[string]string{
+			"USERNAME": m.username,
+			"COUNT":    fmt.Sprint(m.streams),
+		})
+		g.SendMessage(channel, msg)
+	}
+}
+
+// namesLine greets several users at once, the names above the limit are only counted
+func (g *Impl) namesLine(channel, key string, usernames []string) string {
+	if len(usernames) <= maxNamesPerLine {
+		return g.GetChannelString(channel, key, map[string]string{
+			"USERNAMES": strings.Join(usernames, ", "),
+		})
+	}
+
+	return g.GetChannelString(channel, key+"_others", map[string]string{
+		"USERNAMES": strings.Join(usernames[:maxNamesPerLine], ", "),
+		"COUNT":     fmt.Sprint(len(usernames) - maxNamesPerLine),
+	})
+}
@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"fmt"
+	"legion-bot-v2/api/dao"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/killer/custom"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// survivorCommands are listed in every killer's help since they work against any killer
+var survivorCommands = []string{"mend", "heal", "unhook", "hp"}
+
+func (b *Bot) StatsURL(channel string) string {
+	return fmt.Sprintf("%s/#/stats/%s", b.cfg.BaseURL, channel)
+}
+
+// Help returns the structured help of a channel: its commands and every killer with its mechanics.
+func (b *Bot) Help(channel string) dao.HelpResponse {
+	chanState := b.GetState(channel)
+	lang := chanState.Settings.Language
+
+	result := dao.HelpResponse{
+		StatsURL: b.StatsURL(channel),
+		Commands: make([]dao.CommandHelp, 0),
+		Killers:  make([]dao.KillerHelp, 0),
+	}
+
+	for _, command := range b.ListCommands(channel) {
+		if command.Owner != "bot" && command.Owner != "custom" {
+			continue
+		}
+
+		result.Commands = append(result.Commands, b.commandHelp(lang, command))
+	}
+
+	killerNames := make([]string, 0, len(b.killerMap))
+	for name := range b.killerMap {
+		killerNames = append(killerNames, name)
+	}
+	sort.Strings(killerNames)
+
+	for _, name := range killerNames {
+		k := b.killerMap[name]
+
+		killerHelp := dao.KillerHelp{
+			Name:        name,
+			DisplayName: b.GetChannelString(channel, "killer_"+name, nil),
+			Description: b.GetChannelString(channel, "killer_help_"+name, nil),
+			Enabled:     k.Enabled(channel),
+			Commands:    make([]dao.CommandHelp, 0),
+		}
+
+		for _, command := range k.Commands() {
+			killerHelp.Commands = append(killerHelp.Commands, b.commandHelp(lang, command))
+		}
+
+		result.Killers = append(result.Killers, killerHelp)
+	}
+
+	return result
+}
+
+func (b *Bot) commandHelp(lang string, command commands.Command) dao.CommandHelp {
+	description := b.GetLocalString(lang, "command_"+command.Name, nil)
+	if command.Owner == "custom" {
+		description = ""
+	}
+
+	aliases := command.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return dao.CommandHelp{
+		Name:        command.Name,
+		Aliases:     aliases,
+		Permission:  string(command.Permission),
+		Description: description,
+	}
+}
+
+// nextKillerETA describes when the next killer is expected, for chat messages
+func (b *Bot) nextKillerETA(channel string) string {
+	chanState := b.GetState(channel)
+
+	if chanState.Killer != "" {
+		return b.GetChannelString(channel, "killer_active", nil)
+	}
+
+	preview := b.Preview(channel, b.GetCachedStreamStartTime(channel))
+	if preview.NextETA.IsZero() {
+		return "?"
+	}
+
+	return max(time.Until(preview.NextETA), 0).Round(time.Second).String()
+}
+
+func (b *Bot) enabledKillerNames(channel string) []string {
+	help := b.Help(channel)
+
+	var names []string
+	for _, killerHelp := range help.Killers {
+		if killerHelp.Enabled {
+			names = append(names, killerHelp.DisplayName)
+		}
+	}
+
+	return names
+}
+
+func (b *Bot) handleLegionBotCommand(call commands.Call) bool {
+	channel := call.Message.Channel
+
+	var commandNames []string
+	for _, command := range b.ListCommands(channel) {
+		if command.Owner == "bot" && command.Permission == commands.PermissionEveryone {
+			commandNames = append(commandNames, "!"+command.Name)
+		}
+	}
+
+	msg := b.GetChannelString(channel, "legionbot_help", map[string]string{
+		"KILLERS":  strings.Join(b.enabledKillerNames(channel), ", "),
+		"ETA":      b.nextKillerETA(channel),
+		"COMMANDS": strings.Join(commandNames, ", "),
+		"STATS":    b.StatsURL(channel),
+	})
+	b.SendMessage(channel, msg)
+
+	return true
+}
+
+func (b *Bot) handleKillerCommand(call commands.Call) bool {
+	channel := call.Message.Channel
+	chanState := b.GetState(channel)
+	help := b.Help(channel)
+
+	query := strings.ToLower(call.Rest())
+	if query == "" {
+		query = chanState.Killer
+	}
+
+	if query == "" {
+		msg := b.GetChannelString(channel, "killer_help_list", map[string]string{
+			"KILLERS": strings.Join(b.enabledKillerNames(channel), ", "),
+		})
+		b.SendMessage(channel, msg)
+		return true
+	}
+
+	idx := slices.IndexFunc(help.Killers, func(k dao.KillerHelp) bool {
+		return k.Name == query || strings.ToLower(k.DisplayName) == query
+	})
+	if idx < 0 {
+		var names []string
+		for _, killerHelp := range help.Killers {
+			names = append(names, killerHelp.Name)
+		}
+
+		msg := b.GetUserString(channel, call.Message.Username, "killer_help_unknown", map[string]string{
+			"USERNAME": call.Message.Username,
+			"KILLERS":  strings.Join(names, ", "),
+		})
+		b.reply(call.Message, msg)
+		return true
+	}
+
+	killerHelp := help.Killers[idx]
+
+	if def, _, ok := custom.Current(chanState); ok && killerHelp.Name == chanState.Killer {
+		killerHelp.DisplayName = def.DisplayName
+	}
+
+	var commandNames []string
+	for _, command := range killerHelp.Commands {
+		commandNames = append(commandNames, "!"+command.Name)
+	}
+	for _, name := range survivorCommands {
+		commandNames = append(commandNames, "!"+name)
+	}
+
+	msg := b.GetChannelString(channel, "killer_help", map[string]string{
+		"KILLER":      killerHelp.DisplayName,
+		"DESCRIPTION": killerHelp.Description,
+		"COMMANDS":    strings.Join(commandNames, ", "),
+		"STATS":       b.StatsURL(channel),
+	})
+	b.SendMessage(channel, msg)
+
+	return true
+}
@@ -0,0 +1,150 @@
+package highlights
+
+import (
+	"fmt"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// clipDelay lets a big moment play out before it is clipped
+const clipDelay = 5 * time.Second
+
+type Highlights interface {
+	// Clip clips the stream in the background, posts the link to the chat and stores it in the running session
+	Clip(channel, requester string)
+}
+
+var _ Highlights = (*Impl)(nil)
+
+type Impl struct {
+	db.DB
+	chat.Actions
+	i18n.Localiser
+
+	mutex        sync.Mutex
+	lastAutoClip map[string]time.Time
+}
+
+func New(di *do.Injector) Highlights {
+	engine := &Impl{
+		DB:           do.MustInvoke[db.DB](di),
+		Actions:      do.MustInvoke[chat.Actions](di),
+		Localiser:    do.MustInvoke[i18n.Localiser](di),
+		lastAutoClip: make(map[string]time.Time),
+	}
+
+	do.MustInvoke[events.Bus](di).Subscribe(engine.handleEvent)
+
+	return engine
+}
+
+func (e *Impl) Clip(channel, requester string) {
+	sessionStart := e.GetState(channel).Session.Start
+
+	go e.clip(channel, requester, sessionStart)
+}
+
+func (e *Impl) handleEvent(event events.Event) {
+	chanState := e.GetState(event.Channel)
+	clipsSettings := chanState.Settings.Clips
+
+	if clipsSettings == nil {
+		return
+	}
+
+	description, bigMoment := describe(event)
+	if description == "" {
+		return
+	}
+
+	if clipsSettings.Markers {
+		e.CreateStreamMarker(event.Channel, description)
+	}
+
+	if !bigMoment || !clipsSettings.AutoClips || !e.takeAutoClip(event.Channel, clipsSettings.AutoClipCooldown) {
+		return
+	}
+
+	sessionStart := chanState.Session.Start
+	time.AfterFunc(clipDelay, func() {
+		e.clip(event.Channel, "", sessionStart)
+	})
+}
+
+// describe returns the stream marker description of an event and whether it is worth a clip
+func describe(event events.Event) (string, bool) {
+	switch event.Type {
+	case events.TypeSessionStart:
+		return fmt.Sprintf("%s appeared", event.Killer), false
+	case events.TypeSessionEnd:
+		return fmt.Sprintf("%s left", event.Killer), false
+	case events.TypeHook:
+		return fmt.Sprintf("%s hooked %s", event.Killer, event.Username), true
+	case events.TypeStun:
+		return fmt.Sprintf("%s stunned %s", event.Username, event.Killer), true
+	case events.TypeReveal:
+		return fmt.Sprintf("%s revealed %s", event.Username, event.Killer), true
+	}
+
+	return "", false
+}
+
+// takeAutoClip reports whether the cooldown of automatic clips is over and restarts it
+func (e *Impl) takeAutoClip(channel string, cooldown time.Duration) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(e.lastAutoClip[channel]) < cooldown {
+		return false
+	}
+
+	e.lastAutoClip[channel] = now
+	return true
+}
+
+func (e *Impl) clip(channel, requester string, sessionStart time.Time) {
+	url := e.CreateClip(channel)
+	if url == "" {
+		if requester != "" {
+			msg := e.GetChannelString(channel, "clip_failed", map[string]string{"USERNAME": requester})
+			e.SendMessage(channel, msg)
+		}
+		return
+	}
+
+	slog.Info("Clip created",
+		slog.String("channel", channel),
+		slog.String("requester", requester),
+		slog.String("url", url),
+	)
+
+	// the moment may have ended the session while the clip was being created
+	if !sessionStart.IsZero() {
+		e.UpdateState(channel, func(chanState *db.ChannelState) {
+			switch {
+			case chanState.Session.Start.Equal(sessionStart):
+				chanState.Session.Clips = append(chanState.Session.Clips, url)
+			case chanState.LastSession.Start.Equal(sessionStart):
+				chanState.LastSession.Clips = append(chanState.LastSession.Clips, url)
+			}
+		})
+	}
+
+	key := "clip_created"
+	if requester == "" {
+		key = "clip_created_auto"
+	}
+
+	msg := e.GetChannelString(channel, key, map[string]string{
+		"USERNAME": requester,
+		"URL":      url,
+	})
+	e.SendMessage(channel, msg)
+}
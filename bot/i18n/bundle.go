@@ -0,0 +1,236 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// placeholderRegex matches the upper case placeholders like USERNAME or TOPIC_LIST
+var placeholderRegex = regexp.MustCompile(`[A-Z][A-Z_]+[A-Z]`)
+
+// Plural categories, a translation may be an object with some of them instead of a plain string
+const (
+	PluralOne   = "one"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// CountArg is the argument that selects the plural form
+const CountArg = "COUNT"
+
+// entry holds the forms of a single translation, plain strings are stored as the "other" form
+type entry map[string]string
+
+// Bundle contains the translations of all languages
+type Bundle map[string]map[string]entry
+
+// LoadBundle reads the locale files embedded into the binary.
+func LoadBundle() (Bundle, error) {
+	files, err := fs.Glob(localeFS, "locales/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("error finding language files: %v", err)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no language files found")
+	}
+
+	bundle := make(Bundle)
+	for _, file := range files {
+		lang := strings.TrimSuffix(path.Base(file), path.Ext(file))
+
+		data, err := localeFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading language file %s: %v", file, err)
+		}
+
+		translations, err := parseTranslations(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing language file %s: %v", file, err)
+		}
+
+		bundle[lang] = translations
+	}
+
+	if _, ok := bundle[FallbackLanguage]; !ok {
+		return nil, fmt.Errorf("fallback language %q is missing", FallbackLanguage)
+	}
+
+	return bundle, nil
+}
+
+func parseTranslations(data []byte) (map[string]entry, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	translations := make(map[string]entry, len(raw))
+	for key, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			translations[key] = entry{PluralOther: text}
+			continue
+		}
+
+		var forms entry
+		if err := json.Unmarshal(value, &forms); err != nil {
+			return nil, fmt.Errorf("key %s must be a string or an object of plural forms", key)
+		}
+
+		for category := range forms {
+			if !slices.Contains([]string{PluralOne, PluralFew, PluralMany, PluralOther}, category) {
+				return nil, fmt.Errorf("key %s has unknown plural form %q", key, category)
+			}
+		}
+
+		translations[key] = forms
+	}
+
+	return translations, nil
+}
+
+// Languages returns the sorted list of the available languages.
+func (b Bundle) Languages() []string {
+	result := make([]string, 0, len(b))
+	for lang := range b {
+		result = append(result, lang)
+	}
+	slices.Sort(result)
+
+	return result
+}
+
+// Keys returns the sorted keys of all languages.
+func (b Bundle) Keys() []string {
+	keySet := make(map[string]bool)
+	for _, translations := range b {
+		for key := range translations {
+			keySet[key] = true
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+// FallbackChain lists the languages to try for a key: the language itself,
+// its base language for regional variants like "pt-br" and finally the fallback language.
+func FallbackChain(lang string) []string {
+	chain := []string{lang}
+
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		chain = append(chain, base)
+	}
+
+	chain = append(chain, FallbackLanguage)
+
+	return slices.Compact(chain)
+}
+
+// Lookup resolves a key through the fallback chain and picks the plural form for the count.
+func (b Bundle) Lookup(lang, key string, args map[string]string) (string, bool) {
+	for _, l := range FallbackChain(lang) {
+		e, ok := b[l][key]
+		if !ok {
+			continue
+		}
+
+		category := PluralOther
+		if count, err := strconv.Atoi(args[CountArg]); err == nil {
+			category = PluralCategory(l, count)
+		}
+
+		return e.form(category), true
+	}
+
+	return "", false
+}
+
+func (e entry) form(category string) string {
+	for _, c := range []string{category, PluralOther, PluralMany, PluralFew, PluralOne} {
+		if text, ok := e[c]; ok {
+			return text
+		}
+	}
+
+	return ""
+}
+
+// Placeholders returns the placeholders used by any form of a translation.
+func (e entry) Placeholders() []string {
+	var result []string
+	for _, text := range e {
+		result = append(result, placeholderRegex.FindAllString(text, -1)...)
+	}
+
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+// PluralCategory picks the plural form of a count for a language.
+func PluralCategory(lang string, count int) string {
+	if count < 0 {
+		count = -count
+	}
+
+	base, _, _ := strings.Cut(lang, "-")
+
+	switch base {
+	case "ru", "uk":
+		switch {
+		case count%10 == 1 && count%100 != 11:
+			return PluralOne
+		case count%10 >= 2 && count%10 <= 4 && (count%100 < 12 || count%100 > 14):
+			return PluralFew
+		default:
+			return PluralMany
+		}
+	default:
+		if count == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	}
+}
+
+// format fills in the placeholders in a single pass, so values that contain
+// other placeholder names (like a viewer called COUNT) are left alone.
+func format(translation string, args map[string]string) string {
+	if len(args) == 0 {
+		return translation
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+
+	// longer names go first so that KILLER_NAME isn't matched as KILLER
+	slices.SortFunc(names, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	pairs := make([]string, 0, len(args)*2)
+	for _, name := range names {
+		pairs = append(pairs, name, args[name])
+	}
+
+	return strings.NewReplacer(pairs...).Replace(translation)
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"github.com/stretchr/testify/require"
+	"slices"
+	"testing"
+)
+
+// literalWords are upper case words of the English AI prompts that aren't placeholders
+var literalWords = []string{"ONE", "YES", "MAYBE", "PARTIALLY"}
+
+func TestBundleCompleteness(t *testing.T) {
+	bundle, err := LoadBundle()
+	require.NoError(t, err)
+
+	reference := bundle[FallbackLanguage]
+
+	for _, lang := range bundle.Languages() {
+		translations := bundle[lang]
+
+		for key := range reference {
+			require.Contains(t, translations, key, "%s is missing %s", lang, key)
+		}
+
+		for key, e := range translations {
+			require.Contains(t, reference, key, "%s has unknown key %s", lang, key)
+
+			placeholders := slices.DeleteFunc(reference[key].Placeholders(), func(token string) bool {
+				return slices.Contains(literalWords, token)
+			})
+
+			for category, text := range e {
+				for _, placeholder := range placeholders {
+					require.Contains(t, text, placeholder, "%s: %s (%s) is missing a placeholder", lang, key, category)
+				}
+			}
+		}
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	require.Equal(t, PluralOne, PluralCategory("en", 1))
+	require.Equal(t, PluralOther, PluralCategory("en", 2))
+	require.Equal(t, PluralOne, PluralCategory("ru", 21))
+	require.Equal(t, PluralFew, PluralCategory("ru", 3))
+	require.Equal(t, PluralMany, PluralCategory("ru", 12))
+	require.Equal(t, PluralMany, PluralCategory("ru", 25))
+}
+
+func TestLookup(t *testing.T) {
+	bundle, err := LoadBundle()
+	require.NoError(t, err)
+
+	text, ok := bundle.Lookup("ru", "gf_go_away", map[string]string{CountArg: "3"})
+	require.True(t, ok)
+	require.Equal(t, bundle["ru"]["gf_go_away"][PluralFew], text)
+
+	text, ok = bundle.Lookup("xx-yy", "gf_go_away", map[string]string{CountArg: "1"})
+	require.True(t, ok)
+	require.Equal(t, bundle["en"]["gf_go_away"][PluralOne], text)
+
+	_, ok = bundle.Lookup("en", "no_such_key", nil)
+	require.False(t, ok)
+}
+
+func TestFormat(t *testing.T) {
+	result := format("@USERNAME has COUNT bloodpoints", map[string]string{
+		"USERNAME": "COUNT",
+		"COUNT":    "5",
+	})
+	require.Equal(t, "@COUNT has 5 bloodpoints", result)
+}
@@ -2,4 +2,17 @@ package i18n
 
 type Localiser interface {
 	GetLocalString(lang, key string, args map[string]string) string
+	GetChannelString(channel, key string, args map[string]string) string
+	GetUserString(channel, username, key string, args map[string]string) string
+	UserLanguage(channel, username string) string
+	Languages() []string
+	Keys() []KeyInfo
+	ValidateOverride(key, text string) error
+}
+
+// KeyInfo describes a translatable line for the streamers who want to override it
+type KeyInfo struct {
+	Key          string            `json:"key"`
+	Defaults     map[string]string `json:"defaults"`
+	Placeholders []string          `json:"placeholders"`
 }
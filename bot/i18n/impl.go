@@ -1,72 +1,180 @@
 package i18n
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/samber/do"
+	"legion-bot-v2/db"
 	"log/slog"
-	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 )
 
+const (
+	FallbackLanguage  = "en"
+	MaxOverrideLength = 500
+)
+
 type LocaliserImpl struct {
-	translations map[string]map[string]string
+	db.DB
+	bundle       Bundle
+	placeholders map[string][]string
 }
 
-func NewLocaliser() (Localiser, error) {
+func NewLocaliser(di *do.Injector) (Localiser, error) {
+	bundle, err := LoadBundle()
+	if err != nil {
+		return nil, err
+	}
+
 	l := &LocaliserImpl{
-		translations: make(map[string]map[string]string),
+		DB:           do.MustInvoke[db.DB](di),
+		bundle:       bundle,
+		placeholders: make(map[string][]string),
 	}
 
-	files, err := filepath.Glob("bot/i18n/locales/*.json")
-	if err != nil {
-		return nil, fmt.Errorf("error finding language files: %v", err)
+	l.collectPlaceholders()
+
+	return l, nil
+}
+
+// collectPlaceholders finds the placeholders of every key, only tokens present in every language count,
+// so that an upper case word in a single language isn't mistaken for one
+func (l *LocaliserImpl) collectPlaceholders() {
+	for _, key := range l.bundle.Keys() {
+		var placeholders []string
+		first := true
+
+		for _, translations := range l.bundle {
+			e, ok := translations[key]
+			if !ok {
+				continue
+			}
+
+			tokens := e.Placeholders()
+			if first {
+				placeholders = tokens
+				first = false
+				continue
+			}
+
+			placeholders = slices.DeleteFunc(placeholders, func(token string) bool {
+				return !slices.Contains(tokens, token)
+			})
+		}
+
+		l.placeholders[key] = placeholders
+	}
+}
+
+func (l *LocaliserImpl) GetLocalString(lang, key string, args map[string]string) string {
+	return format(l.lookup(lang, key, args), args)
+}
+
+// GetChannelString resolves a key for a channel: the streamer's override first,
+// then the channel language and its fallback chain.
+func (l *LocaliserImpl) GetChannelString(channel, key string, args map[string]string) string {
+	chanState := l.GetState(channel)
+
+	if override, ok := chanState.Settings.LocaleOverrides[key]; ok && override != "" {
+		return format(override, args)
+	}
+
+	return format(l.lookup(chanState.Settings.Language, key, args), args)
+}
+
+// GetUserString resolves a key for a reply aimed at a single user.
+// Users who picked their own language get the translation in it, everyone else gets the channel string.
+func (l *LocaliserImpl) GetUserString(channel, username, key string, args map[string]string) string {
+	chanState := l.GetState(channel)
+
+	user, ok := chanState.UserMap[username]
+	if !ok || user.Language == "" || user.Language == chanState.Settings.Language {
+		return l.GetChannelString(channel, key, args)
 	}
 
-	if len(files) == 0 {
-		return nil, fmt.Errorf("no language files found")
+	return format(l.lookup(user.Language, key, args), args)
+}
+
+// UserLanguage returns the language a user prefers in a channel.
+func (l *LocaliserImpl) UserLanguage(channel, username string) string {
+	chanState := l.GetState(channel)
+
+	if user, ok := chanState.UserMap[username]; ok && user.Language != "" {
+		return user.Language
 	}
 
-	for _, file := range files {
-		lang := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	return chanState.Settings.Language
+}
+
+func (l *LocaliserImpl) Languages() []string {
+	return l.bundle.Languages()
+}
+
+func (l *LocaliserImpl) lookup(lang, key string, args map[string]string) string {
+	if translation, ok := l.bundle.Lookup(lang, key, args); ok {
+		return translation
+	}
 
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("error reading language file %s: %v", file, err)
+	slog.Error("Language key not found",
+		slog.String("lang", lang),
+		slog.String("key", key),
+	)
+	return key
+}
+
+func (l *LocaliserImpl) Keys() []KeyInfo {
+	keys := l.bundle.Keys()
+
+	result := make([]KeyInfo, 0, len(keys))
+	for _, key := range keys {
+		defaults := make(map[string]string)
+		for lang, translations := range l.bundle {
+			if e, ok := translations[key]; ok {
+				defaults[lang] = e.form(PluralOther)
+			}
 		}
 
-		var translations map[string]string
-		if err := json.Unmarshal(data, &translations); err != nil {
-			return nil, fmt.Errorf("error parsing language file %s: %v", file, err)
+		placeholders := l.placeholders[key]
+		if placeholders == nil {
+			placeholders = []string{}
 		}
 
-		l.translations[lang] = translations
+		result = append(result, KeyInfo{
+			Key:          key,
+			Defaults:     defaults,
+			Placeholders: placeholders,
+		})
 	}
 
-	return l, nil
+	return result
 }
 
-func (l *LocaliserImpl) GetLocalString(lang, key string, args map[string]string) string {
-	langTranslations, ok := l.translations[lang]
+// ValidateOverride checks that an override belongs to a known key and keeps all of its placeholders.
+func (l *LocaliserImpl) ValidateOverride(key, text string) error {
+	placeholders, ok := l.placeholders[key]
 	if !ok {
-		slog.Error("Language not found",
-			slog.String("lang", lang),
-		)
-		return key
+		return fmt.Errorf("unknown key %q", key)
 	}
 
-	translation, ok := langTranslations[key]
-	if !ok {
-		slog.Error("Language key not found",
-			slog.String("lang", lang),
-			slog.String("key", key),
-		)
-		return key
+	if strings.TrimSpace(text) == "" {
+		return errors.New("text must not be empty")
+	}
+
+	if len([]rune(text)) > MaxOverrideLength {
+		return fmt.Errorf("text must be at most %d characters long", MaxOverrideLength)
+	}
+
+	var missing []string
+	for _, placeholder := range placeholders {
+		if !strings.Contains(text, placeholder) {
+			missing = append(missing, placeholder)
+		}
 	}
 
-	for k, v := range args {
-		translation = strings.ReplaceAll(translation, k, v)
+	if len(missing) > 0 {
+		return fmt.Errorf("missing placeholders: %s", strings.Join(missing, ", "))
 	}
 
-	return translation
+	return nil
 }
@@ -0,0 +1,395 @@
+package custom
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/protection"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"legion-bot-v2/util/timers"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+var _ killer.Killer = (*Engine)(nil)
+
+const (
+	SessionTimerName = "!!custom!!"
+)
+
+// Engine interprets the declarative custom killers of a channel.
+// All of them share the single "custom" killer slot, the definition in play is kept in the killer state.
+type Engine struct {
+	db.DB
+	chat.Actions
+	timers.Timers
+	i18n.Localiser
+	events.Bus
+}
+
+func New(di *do.Injector) *Engine {
+	return &Engine{
+		DB:        do.MustInvoke[db.DB](di),
+		Actions:   do.MustInvoke[chat.Actions](di),
+		Timers:    do.MustInvoke[timers.Timers](di),
+		Localiser: do.MustInvoke[i18n.Localiser](di),
+		Bus:       do.MustInvoke[events.Bus](di),
+	}
+}
+
+// Current returns the custom killer of the running session, if any.
+func Current(chanState db.ChannelState) (db.CustomKiller, db.CustomKillerState, bool) {
+	if chanState.Killer != "custom" {
+		return db.CustomKiller{}, db.CustomKillerState{}, false
+	}
+
+	var state db.CustomKillerState
+	if err := mapstructure.Decode(chanState.KillerState, &state); err != nil {
+		slog.Error("Failed to decode killer state",
+			slog.String("channel", chanState.Channel),
+			slog.Any("error", err),
+		)
+		return db.CustomKiller{}, db.CustomKillerState{}, false
+	}
+
+	for _, def := range chanState.Settings.CustomKillers {
+		if def.Name == state.Name {
+			return def, state, true
+		}
+	}
+
+	return db.CustomKiller{}, state, false
+}
+
+func enabledKillers(chanState db.ChannelState) []db.CustomKiller {
+	var result []db.CustomKiller
+	for _, def := range chanState.Settings.CustomKillers {
+		if def.Enabled {
+			result = append(result, def)
+		}
+	}
+	return result
+}
+
+func (e *Engine) Name() string {
+	return "custom"
+}
+
+func (e *Engine) Weight(channel string) int {
+	chanState := e.GetState(channel)
+
+	var weight int
+	for _, def := range enabledKillers(chanState) {
+		weight += def.Weight
+	}
+
+	return weight
+}
+
+func (e *Engine) Scopes() []string {
+	return []string{chat.ScopeBannedUsers, chat.ScopeChatMessages}
+}
+
+func (e *Engine) Enabled(channel string) bool {
+	chanState := e.GetState(channel)
+	return len(enabledKillers(chanState)) > 0
+}
+
+func (e *Engine) Commands() []commands.Command {
+	return nil
+}
+
+func (e *Engine) FixSettings(_ *db.ChannelState) bool {
+	return false
+}
+
+func (e *Engine) Start(userMsg db.Message) {
+	channel := userMsg.Channel
+	startState := e.GetState(channel)
+	now := time.Now()
+
+	if startState.Killer != "" {
+		slog.Warn("Killer is already summoned",
+			slog.String("channel", channel),
+		)
+		return
+	}
+
+	def, ok := pickWeighted(enabledKillers(startState))
+	if !ok {
+		slog.Warn("No custom killers are enabled",
+			slog.String("channel", channel),
+		)
+		return
+	}
+
+	e.UpdateState(channel, func(channelState *db.ChannelState) {
+		channelState.Killer = "custom"
+		channelState.KillerState = db.CustomKillerState{
+			Name: def.Name,
+		}
+		channelState.Date = now
+		channelState.Stats["total"]++
+	})
+
+	if def.StartMessage != "" {
+		e.SendMessage(channel, render(def.StartMessage, def, channel, ""))
+	}
+
+	e.StartTimer(channel, SessionTimerName, def.End.Timeout, func() {
+		e.endSession(channel, def, "fail")
+	})
+
+	slog.Info("Custom killer started",
+		slog.String("channel", channel),
+		slog.String("killer", def.Name),
+	)
+}
+
+func (e *Engine) HandleMessage(userMsg db.Message) {
+	channel := userMsg.Channel
+	chanState := e.GetState(channel)
+	now := time.Now()
+
+	if chanState.Settings.Disabled {
+		return
+	}
+
+	def, state, ok := Current(chanState)
+	if !ok {
+		slog.Warn("Custom killer definition is missing, ending session",
+			slog.String("channel", channel),
+			slog.String("killer", state.Name),
+		)
+		e.endSession(channel, db.CustomKiller{}, "fail")
+		return
+	}
+
+	if now.Sub(chanState.Date) < def.MinDelayBetweenTriggers {
+		return
+	}
+
+	user := chanState.UserMap[userMsg.Username]
+	if user == nil || user.Health == "dead" || user.Health == "hooked" {
+		return
+	}
+
+	trigger, ok := matchTrigger(def, userMsg)
+	if !ok {
+		return
+	}
+
+	protected := protection.Spectator(channel, userMsg.Username) || user.IsImmune()
+
+	e.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.Date = now
+	})
+
+	for _, effect := range trigger.Effects {
+		if e.applyEffect(userMsg, def, &state, effect, protected) {
+			return
+		}
+	}
+
+	e.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.KillerState = state
+	})
+
+	switch {
+	case def.End.MaxHooks > 0 && state.Hooks >= def.End.MaxHooks:
+		e.endSession(channel, def, "success")
+	case def.End.MaxHits > 0 && state.Hits >= def.End.MaxHits:
+		e.endSession(channel, def, "success")
+	}
+}
+
+func (e *Engine) HandleWhisper(_ db.PartialMessage) {
+
+}
+
+func (e *Engine) TimeRemaining(channel string) time.Duration {
+	return e.GetRemainingTime(channel, SessionTimerName)
+}
+
+// applyEffect applies a single effect and reports whether the session has ended.
+func (e *Engine) applyEffect(userMsg db.Message, def db.CustomKiller, state *db.CustomKillerState, effect db.CustomKillerEffect, protected bool) bool {
+	channel := userMsg.Channel
+	username := userMsg.Username
+
+	switch effect.Type {
+	case db.CustomKillerEffectSend:
+		if state.Messages >= MaxMessagesPerSession {
+			return false
+		}
+		state.Messages++
+
+		e.SendMessage(channel, render(effect.Text, def, channel, username))
+
+	case db.CustomKillerEffectDelete:
+		if protected {
+			return false
+		}
+
+		e.DeleteMessage(channel, userMsg.ID)
+
+	case db.CustomKillerEffectInjure:
+		if protected {
+			return false
+		}
+
+		user := e.GetState(channel).UserMap[username]
+		if user.Health == "injured" {
+			e.hook(channel, username, def, state)
+			return false
+		}
+
+		state.Hits++
+		e.UpdateState(channel, func(chanState *db.ChannelState) {
+			chanState.Stats["hits"]++
+			chanState.UserMap[username].Health = "injured"
+			chanState.UserMap[username].Stats["hits"]++
+		})
+
+	case db.CustomKillerEffectHook:
+		if protected {
+			return false
+		}
+
+		e.hook(channel, username, def, state)
+
+	case db.CustomKillerEffectTimeout:
+		if protected || state.Timeouts >= MaxTimeoutsPerSession {
+			return false
+		}
+		state.Timeouts++
+
+		e.TimeoutUser(channel, username, min(effect.Duration, MaxTimeout), "")
+
+	case db.CustomKillerEffectEnd:
+		e.UpdateState(channel, func(chanState *db.ChannelState) {
+			chanState.KillerState = *state
+		})
+		e.endSession(channel, def, "success")
+		return true
+	}
+
+	return false
+}
+
+func (e *Engine) hook(channel, username string, def db.CustomKiller, state *db.CustomKillerState) {
+	state.Hooks++
+
+	e.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.UserMap[username].Health = "hooked"
+		chanState.UserMap[username].Stats["hooks"]++
+	})
+
+	e.Emit(events.Event{Channel: channel, Username: username, Type: events.TypeHook, Killer: e.Name()})
+
+	if state.Timeouts >= MaxTimeoutsPerSession {
+		return
+	}
+	state.Timeouts++
+
+	e.TimeoutUser(channel, username, min(def.HookBanTime, MaxTimeout), "")
+}
+
+func (e *Engine) endSession(channel string, def db.CustomKiller, outcome string) {
+	chanState := e.GetState(channel)
+	if chanState.Killer != "custom" {
+		return
+	}
+
+	e.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.Killer = ""
+		chanState.KillerState = nil
+		chanState.Date = time.Now()
+		chanState.Stats[outcome]++
+	})
+
+	e.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: e.Name()})
+
+	e.StopTimer(channel, SessionTimerName)
+
+	if def.EndMessage != "" {
+		e.SendMessage(channel, render(def.EndMessage, def, channel, ""))
+	}
+}
+
+func matchTrigger(def db.CustomKiller, userMsg db.Message) (db.CustomKillerTrigger, bool) {
+	text := strings.TrimSpace(userMsg.Text)
+
+	for _, trigger := range def.Triggers {
+		switch trigger.Type {
+		case db.CustomKillerTriggerCommand:
+			command, _, _ := strings.Cut(strings.ToLower(text), " ")
+			if command != trigger.Command {
+				continue
+			}
+
+		case db.CustomKillerTriggerRegex:
+			re, err := compilePattern(trigger.Pattern)
+			if err != nil || !re.MatchString(text) {
+				continue
+			}
+
+		case db.CustomKillerTriggerRandom:
+
+		default:
+			continue
+		}
+
+		if rand.Float64() > trigger.Chance {
+			continue
+		}
+
+		return trigger, true
+	}
+
+	return db.CustomKillerTrigger{}, false
+}
+
+// render fills in the template placeholders, the result is truncated so a definition can't produce huge messages.
+func render(text string, def db.CustomKiller, channel, username string) string {
+	result := strings.NewReplacer(
+		"USERNAME", username,
+		"KILLER", def.DisplayName,
+		"CHANNEL", channel,
+	).Replace(text)
+
+	runes := []rune(result)
+	if len(runes) > MaxTextLength {
+		result = string(runes[:MaxTextLength])
+	}
+
+	return result
+}
+
+func pickWeighted(defs []db.CustomKiller) (db.CustomKiller, bool) {
+	totalWeight := 0
+	for _, def := range defs {
+		totalWeight += def.Weight
+	}
+
+	if totalWeight <= 0 {
+		return db.CustomKiller{}, false
+	}
+
+	r := rand.IntN(totalWeight)
+
+	runningTotal := 0
+	for _, def := range defs {
+		runningTotal += def.Weight
+		if r < runningTotal {
+			return def, true
+		}
+	}
+
+	return defs[len(defs)-1], true
+}
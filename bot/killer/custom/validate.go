@@ -0,0 +1,206 @@
+package custom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jellydator/ttlcache/v3"
+	"gopkg.in/yaml.v3"
+	"legion-bot-v2/db"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits keep custom killers from flooding the chat or handing out unlimited timeouts.
+const (
+	MaxKillers            = 10
+	MaxTriggers           = 10
+	MaxEffects            = 5
+	MaxPatternLength      = 200
+	MaxTextLength         = 300
+	MaxTimeout            = 10 * time.Minute
+	MaxSessionTimeout     = 15 * time.Minute
+	MaxTimeoutsPerSession = 5
+	MaxMessagesPerSession = 30
+)
+
+var (
+	nameRegex    = regexp.MustCompile(`^[a-z0-9_]{3,24}$`)
+	commandRegex = regexp.MustCompile(`^![a-z0-9_]{1,24}$`)
+)
+
+// patterns caches the compiled regex triggers. Patterns dropped by edits expire
+// and the capacity evicts the least recently matched ones, so uploads can't grow it forever.
+var patterns = ttlcache.New(
+	ttlcache.WithTTL[string, *regexp.Regexp](time.Hour),
+	ttlcache.WithCapacity[string, *regexp.Regexp](1000),
+)
+
+// compilePattern compiles the trigger pattern once, validation fills the cache
+// and the triggers of definitions stored before a restart are compiled on their first message.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if item := patterns.Get(pattern); item != nil {
+		return item.Value(), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns.Set(pattern, re, ttlcache.DefaultTTL)
+
+	return re, nil
+}
+
+// Parse decodes a custom killer from JSON or YAML. JSON durations are in nanoseconds
+// like in the rest of the settings, YAML ones are written as "1m30s".
+func Parse(data []byte) (db.CustomKiller, error) {
+	var k db.CustomKiller
+
+	unmarshal := yaml.Unmarshal
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		unmarshal = json.Unmarshal
+	}
+
+	if err := unmarshal(data, &k); err != nil {
+		return db.CustomKiller{}, fmt.Errorf("invalid custom killer: %v", err)
+	}
+
+	return Validate(k)
+}
+
+// ValidateAll validates all custom killers of a channel.
+func ValidateAll(killers []db.CustomKiller) ([]db.CustomKiller, error) {
+	if len(killers) > MaxKillers {
+		return nil, fmt.Errorf("too many custom killers, at most %d are allowed", MaxKillers)
+	}
+
+	result := make([]db.CustomKiller, 0, len(killers))
+	names := make(map[string]bool)
+
+	for _, k := range killers {
+		k, err := Validate(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if names[k.Name] {
+			return nil, fmt.Errorf("duplicate killer name %q", k.Name)
+		}
+		names[k.Name] = true
+
+		result = append(result, k)
+	}
+
+	return result, nil
+}
+
+// Validate checks a custom killer against the sandbox limits and fills in defaults.
+func Validate(k db.CustomKiller) (db.CustomKiller, error) {
+	k.Name = strings.ToLower(strings.TrimSpace(k.Name))
+	if !nameRegex.MatchString(k.Name) {
+		return k, errors.New("name must be 3-24 lowercase letters, digits or underscores")
+	}
+
+	if k.DisplayName == "" {
+		k.DisplayName = k.Name
+	}
+	if k.Weight <= 0 {
+		k.Weight = 100
+	}
+	if k.HookBanTime <= 0 {
+		k.HookBanTime = time.Minute
+	}
+	if k.MinDelayBetweenTriggers <= 0 {
+		k.MinDelayBetweenTriggers = 5 * time.Second
+	}
+
+	if utf8.RuneCountInString(k.DisplayName) > MaxTextLength || utf8.RuneCountInString(k.StartMessage) > MaxTextLength || utf8.RuneCountInString(k.EndMessage) > MaxTextLength {
+		return k, fmt.Errorf("%s: texts must be at most %d characters long", k.Name, MaxTextLength)
+	}
+
+	if k.HookBanTime > MaxTimeout {
+		return k, fmt.Errorf("%s: hook ban time must be at most %s", k.Name, MaxTimeout)
+	}
+
+	if k.End.Timeout <= 0 || k.End.Timeout > MaxSessionTimeout {
+		return k, fmt.Errorf("%s: end timeout must be between 0 and %s", k.Name, MaxSessionTimeout)
+	}
+
+	if k.End.MaxHooks < 0 || k.End.MaxHits < 0 {
+		return k, fmt.Errorf("%s: end conditions must not be negative", k.Name)
+	}
+
+	if len(k.Triggers) == 0 || len(k.Triggers) > MaxTriggers {
+		return k, fmt.Errorf("%s: between 1 and %d triggers are required", k.Name, MaxTriggers)
+	}
+
+	for i := range k.Triggers {
+		trigger := &k.Triggers[i]
+
+		if err := validateTrigger(trigger); err != nil {
+			return k, fmt.Errorf("%s: trigger #%d: %v", k.Name, i+1, err)
+		}
+	}
+
+	return k, nil
+}
+
+func validateTrigger(trigger *db.CustomKillerTrigger) error {
+	switch trigger.Type {
+	case db.CustomKillerTriggerCommand:
+		trigger.Command = strings.ToLower(strings.TrimSpace(trigger.Command))
+		if !commandRegex.MatchString(trigger.Command) {
+			return errors.New("command must look like !name")
+		}
+
+	case db.CustomKillerTriggerRegex:
+		if trigger.Pattern == "" || len(trigger.Pattern) > MaxPatternLength {
+			return fmt.Errorf("pattern must be 1-%d characters long", MaxPatternLength)
+		}
+		if _, err := compilePattern(trigger.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+
+	case db.CustomKillerTriggerRandom:
+
+	default:
+		return fmt.Errorf("unknown trigger type %q", trigger.Type)
+	}
+
+	if trigger.Chance == 0 {
+		trigger.Chance = 1
+	}
+	if trigger.Chance < 0 || trigger.Chance > 1 {
+		return errors.New("chance must be between 0 and 1")
+	}
+
+	if len(trigger.Effects) == 0 || len(trigger.Effects) > MaxEffects {
+		return fmt.Errorf("between 1 and %d effects are required", MaxEffects)
+	}
+
+	for _, effect := range trigger.Effects {
+		switch effect.Type {
+		case db.CustomKillerEffectInjure, db.CustomKillerEffectHook, db.CustomKillerEffectDelete, db.CustomKillerEffectEnd:
+
+		case db.CustomKillerEffectSend:
+			if effect.Text == "" || utf8.RuneCountInString(effect.Text) > MaxTextLength {
+				return fmt.Errorf("send text must be 1-%d characters long", MaxTextLength)
+			}
+
+		case db.CustomKillerEffectTimeout:
+			if effect.Duration <= 0 || effect.Duration > MaxTimeout {
+				return fmt.Errorf("timeout duration must be between 0 and %s", MaxTimeout)
+			}
+
+		default:
+			return fmt.Errorf("unknown effect type %q", effect.Type)
+		}
+	}
+
+	return nil
+}
@@ -1,18 +1,18 @@
 package doctor
 
 import (
-	"fmt"
 	"github.com/samber/do"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/protection"
 	"legion-bot-v2/db"
 	"legion-bot-v2/gpt"
 	"legion-bot-v2/twitch/chat"
-	"legion-bot-v2/util"
 	"legion-bot-v2/util/timers"
 	"log/slog"
 	"math/rand/v2"
-	"strings"
 	"time"
 )
 
@@ -28,6 +28,7 @@ type Doctor struct {
 	timers.Timers
 	i18n.Localiser
 	gpt.Gpt
+	events.Bus
 }
 
 func New(di *do.Injector) *Doctor {
@@ -37,6 +38,7 @@ func New(di *do.Injector) *Doctor {
 		Timers:    do.MustInvoke[timers.Timers](di),
 		Localiser: do.MustInvoke[i18n.Localiser](di),
 		Gpt:       do.MustInvoke[gpt.Gpt](di),
+		Bus:       do.MustInvoke[events.Bus](di),
 	}
 }
 
@@ -49,6 +51,10 @@ func (d *Doctor) Weight(channel string) int {
 	return chanState.Settings.Killers.Doctor.Weight
 }
 
+func (d *Doctor) Scopes() []string {
+	return []string{chat.ScopeBannedUsers, chat.ScopeChatMessages}
+}
+
 func (d *Doctor) Enabled(channel string) bool {
 	chanState := d.GetState(channel)
 	return chanState.Settings.Killers.Doctor.Enabled
@@ -68,8 +74,7 @@ func (d *Doctor) startMadnessTimer(channel string) {
 	d.StopTimer(channel, MadnessTimerName)
 
 	chanState := d.GetState(channel)
-	doctorSettings := chanState.Settings.Killers.Doctor
-	lang := chanState.Settings.Language
+	doctorSettings := chanState.EffectiveKillers().Doctor
 
 	d.StartTimer(channel, MadnessTimerName, doctorSettings.Timeout, func() {
 		d.UpdateState(channel, func(chanState *db.ChannelState) {
@@ -79,14 +84,15 @@ func (d *Doctor) startMadnessTimer(channel string) {
 			chanState.Stats["fail"]++
 		})
 
-		msg := d.GetLocalString(lang, "doctor_go_away", map[string]string{})
+		d.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: d.Name()})
+
+		msg := d.GetChannelString(channel, "doctor_go_away", map[string]string{})
 		d.SendMessage(channel, msg)
 	})
 }
 
 func (d *Doctor) startMadness(channel string) {
 	startState := d.GetState(channel)
-	lang := startState.Settings.Language
 	now := time.Now()
 
 	if startState.Killer != "" {
@@ -102,7 +108,7 @@ func (d *Doctor) startMadness(channel string) {
 		channelState.Stats["total"]++
 	})
 
-	msg := d.GetLocalString(lang, "start_doctor", nil)
+	msg := d.GetChannelString(channel, "start_doctor", nil)
 	d.SendMessage(channel, msg)
 
 	d.startMadnessTimer(channel)
@@ -116,17 +122,13 @@ func (d *Doctor) Start(userMsg db.Message) {
 
 func (d *Doctor) HandleMessage(userMsg db.Message) {
 	chanState := d.GetState(userMsg.Channel)
-	doctorSettings := chanState.Settings.Killers.Doctor
+	doctorSettings := chanState.EffectiveKillers().Doctor
 	now := time.Now()
 
 	if chanState.Settings.Disabled {
 		return
 	}
 
-	if d.handleCommands(userMsg) {
-		return
-	}
-
 	user := chanState.UserMap[userMsg.Username]
 	diff := now.Sub(chanState.Date)
 
@@ -142,7 +144,7 @@ func (d *Doctor) HandleMessage(userMsg db.Message) {
 		return
 	}
 
-	if userMsg.Username == userMsg.Channel || userMsg.IsMod || strings.Contains(userMsg.Username, "bot") || userMsg.Username == util.BotOwner {
+	if protection.Spectator(userMsg.Channel, userMsg.Username) || user.IsImmune() {
 		return
 	}
 
@@ -157,18 +159,8 @@ func (d *Doctor) TimeRemaining(channel string) time.Duration {
 	return d.GetRemainingTime(channel, MadnessTimerName)
 }
 
-func (d *Doctor) handleCommands(userMsg db.Message) bool {
-	chanState := d.GetState(userMsg.Channel)
-	lang := chanState.Settings.Language
-
-	switch {
-	case strings.HasPrefix(userMsg.Text, "!killer"):
-		msg := d.GetLocalString(lang, "commands_doctor", map[string]string{"STATS": fmt.Sprintf("https://leg.rofleksey.ru/#/stats/%s", userMsg.Channel)})
-		d.SendMessage(userMsg.Channel, msg)
-		return true
-	}
-
-	return false
+func (d *Doctor) Commands() []commands.Command {
+	return nil
 }
 
 func (d *Doctor) handleHit(userMsg db.Message) {
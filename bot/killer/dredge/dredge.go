@@ -3,12 +3,14 @@ package dredge
 import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/samber/do"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/protection"
 	"legion-bot-v2/db"
 	"legion-bot-v2/gpt"
 	"legion-bot-v2/twitch/chat"
-	"legion-bot-v2/util"
 	"legion-bot-v2/util/timers"
 	"log/slog"
 	"strings"
@@ -27,6 +29,7 @@ type Dredge struct {
 	timers.Timers
 	i18n.Localiser
 	gpt.Gpt
+	events.Bus
 }
 
 func New(di *do.Injector) *Dredge {
@@ -36,6 +39,7 @@ func New(di *do.Injector) *Dredge {
 		Timers:    do.MustInvoke[timers.Timers](di),
 		Localiser: do.MustInvoke[i18n.Localiser](di),
 		Gpt:       do.MustInvoke[gpt.Gpt](di),
+		Bus:       do.MustInvoke[events.Bus](di),
 	}
 }
 
@@ -48,11 +52,19 @@ func (d *Dredge) Weight(channel string) int {
 	return chanState.Settings.Killers.Dredge.Weight
 }
 
+func (d *Dredge) Scopes() []string {
+	return []string{chat.ScopeBannedUsers, chat.ScopeChatSettings}
+}
+
 func (d *Dredge) Enabled(channel string) bool {
 	chanState := d.GetState(channel)
 	return chanState.Settings.Killers.Dredge.Enabled
 }
 
+func (d *Dredge) Commands() []commands.Command {
+	return nil
+}
+
 func (d *Dredge) FixSettings(chanState *db.ChannelState) bool {
 	if chanState.Settings.Killers.Dredge != nil {
 		return false
@@ -67,7 +79,7 @@ func (d *Dredge) startNightfallTimer(channel string) {
 	d.StopTimer(channel, NightfallTimer)
 
 	chanState := d.GetState(channel)
-	dredgeSettings := chanState.Settings.Killers.Dredge
+	dredgeSettings := chanState.EffectiveKillers().Dredge
 
 	d.StartTimer(channel, NightfallTimer, dredgeSettings.Timeout, func() {
 		d.onNightfallEnd(channel)
@@ -76,8 +88,7 @@ func (d *Dredge) startNightfallTimer(channel string) {
 
 func (d *Dredge) onNightfallEnd(channel string) {
 	chanState := d.GetState(channel)
-	dredgeSettings := chanState.Settings.Killers.Dredge
-	lang := chanState.Settings.Language
+	dredgeSettings := chanState.EffectiveKillers().Dredge
 
 	d.SetEmoteMode(channel, false)
 
@@ -89,7 +100,7 @@ func (d *Dredge) onNightfallEnd(channel string) {
 		)
 	}
 
-	var counters map[string]int
+	counters := make(map[string]int)
 	for _, otherUsername := range dredgeState.Votes {
 		counters[otherUsername]++
 	}
@@ -110,6 +121,12 @@ func (d *Dredge) onNightfallEnd(channel string) {
 		usernamesToHook = append(usernamesToHook, username)
 	}
 
+	if len(usernamesToHook) == 1 {
+		if user, ok := chanState.UserMap[usernamesToHook[0]]; !ok || user.IsImmune() || protection.Spectator(channel, usernamesToHook[0]) {
+			usernamesToHook = nil
+		}
+	}
+
 	if maxCounter <= 1 || len(usernamesToHook) != 1 {
 		d.UpdateState(channel, func(chanState *db.ChannelState) {
 			chanState.Killer = ""
@@ -118,7 +135,9 @@ func (d *Dredge) onNightfallEnd(channel string) {
 			chanState.Stats["fail"]++
 		})
 
-		msg := d.GetLocalString(lang, "dredge_go_away", map[string]string{})
+		d.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: d.Name()})
+
+		msg := d.GetChannelString(channel, "dredge_go_away", map[string]string{})
 		d.SendMessage(channel, msg)
 		return
 	}
@@ -134,15 +153,17 @@ func (d *Dredge) onNightfallEnd(channel string) {
 		chanState.Stats["success"]++
 	})
 
+	d.Emit(events.Event{Channel: channel, Username: username, Type: events.TypeHook, Killer: d.Name()})
+	d.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: d.Name()})
+
 	d.TimeoutUser(channel, username, dredgeSettings.HookBanTime, "")
 
-	msg := d.GetLocalString(lang, "dredge_hit_dead", map[string]string{"USERNAME": username})
+	msg := d.GetChannelString(channel, "dredge_hit_dead", map[string]string{"USERNAME": username})
 	d.SendMessage(channel, msg)
 }
 
 func (d *Dredge) startNightfall(channel string) {
 	startState := d.GetState(channel)
-	lang := startState.Settings.Language
 	now := time.Now()
 
 	if startState.Killer != "" {
@@ -161,7 +182,7 @@ func (d *Dredge) startNightfall(channel string) {
 		channelState.Stats["total"]++
 	})
 
-	msg := d.GetLocalString(lang, "start_dredge", nil)
+	msg := d.GetChannelString(channel, "start_dredge", nil)
 	d.SendMessage(channel, msg)
 
 	d.SetEmoteMode(channel, true)
@@ -185,7 +206,7 @@ func (d *Dredge) HandleWhisper(userMsg db.PartialMessage) {
 		return
 	}
 
-	if userMsg.Username == userMsg.Channel || strings.Contains(userMsg.Username, "bot") || userMsg.Username == util.BotOwner {
+	if protection.Spectator(userMsg.Channel, userMsg.Username) {
 		return
 	}
 
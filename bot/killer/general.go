@@ -1,6 +1,7 @@
 package killer
 
 import (
+	"legion-bot-v2/bot/commands"
 	"legion-bot-v2/db"
 	"time"
 )
@@ -10,6 +11,9 @@ type Killer interface {
 	Enabled(channel string) bool
 	FixSettings(chanState *db.ChannelState) bool
 	Weight(channel string) int
+	// Scopes lists the token scopes the killer needs on top of the bot being a moderator
+	Scopes() []string
+	Commands() []commands.Command
 	Start(userMsg db.Message)
 	HandleMessage(userMsg db.Message)
 	HandleWhisper(userMsg db.PartialMessage)
@@ -4,16 +4,17 @@ import (
 	"fmt"
 	"github.com/mitchellh/mapstructure"
 	"github.com/samber/do"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/protection"
 	"legion-bot-v2/db"
 	"legion-bot-v2/gpt"
 	"legion-bot-v2/twitch/chat"
-	"legion-bot-v2/util"
 	"legion-bot-v2/util/timers"
 	"log/slog"
 	"math/rand/v2"
-	"strings"
 	"time"
 )
 
@@ -29,6 +30,8 @@ type GhostFace struct {
 	timers.Timers
 	i18n.Localiser
 	gpt.Gpt
+	events.Bus
+	commands.Registry
 }
 
 func New(di *do.Injector) *GhostFace {
@@ -38,6 +41,8 @@ func New(di *do.Injector) *GhostFace {
 		Timers:    do.MustInvoke[timers.Timers](di),
 		Localiser: do.MustInvoke[i18n.Localiser](di),
 		Gpt:       do.MustInvoke[gpt.Gpt](di),
+		Bus:       do.MustInvoke[events.Bus](di),
+		Registry:  do.MustInvoke[commands.Registry](di),
 	}
 }
 
@@ -54,6 +59,10 @@ func (g *GhostFace) Weight(channel string) int {
 	return chanState.Settings.Killers.GhostFace.Weight
 }
 
+func (g *GhostFace) Scopes() []string {
+	return []string{chat.ScopeBannedUsers}
+}
+
 func (g *GhostFace) Enabled(channel string) bool {
 	chanState := g.GetState(channel)
 	return chanState.Settings.Killers.GhostFace.Enabled
@@ -73,8 +82,7 @@ func (g *GhostFace) startStalkTimer(channel string) {
 	g.StopTimer(channel, StalkTimerName)
 
 	chanState := g.GetState(channel)
-	gfSettings := chanState.Settings.Killers.GhostFace
-	lang := chanState.Settings.Language
+	gfSettings := chanState.EffectiveKillers().GhostFace
 
 	g.StartTimer(channel, StalkTimerName, gfSettings.Timeout, func() {
 		chanState := g.GetState(channel)
@@ -94,14 +102,15 @@ func (g *GhostFace) startStalkTimer(channel string) {
 			chanState.Stats["fail"]++
 		})
 
-		msg := g.GetLocalString(lang, "gf_go_away", map[string]string{"COUNT": fmt.Sprint(len(gfState.StalkedThisRound))})
+		g.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: g.Name()})
+
+		msg := g.GetChannelString(channel, "gf_go_away", map[string]string{"COUNT": fmt.Sprint(len(gfState.StalkedThisRound))})
 		g.SendMessage(channel, msg)
 	})
 }
 
 func (g *GhostFace) startStalk(channel string) {
 	startState := g.GetState(channel)
-	lang := startState.Settings.Language
 	now := time.Now()
 
 	if startState.Killer != "" {
@@ -117,10 +126,13 @@ func (g *GhostFace) startStalk(channel string) {
 		channelState.Stats["total"]++
 		channelState.KillerState = db.GhostFaceState{
 			StalkedThisRound: make(map[string]bool),
+			RevealAttempts:   make(map[string]int),
 		}
 	})
 
-	msg := g.GetLocalString(lang, "start_gf", nil)
+	g.RegisterCommands(channel, g.Name(), g.Commands()...)
+
+	msg := g.GetChannelString(channel, "start_gf", nil)
 	g.SendMessage(channel, msg)
 
 	g.startStalkTimer(channel)
@@ -134,17 +146,13 @@ func (g *GhostFace) Start(userMsg db.Message) {
 
 func (g *GhostFace) HandleMessage(userMsg db.Message) {
 	chanState := g.GetState(userMsg.Channel)
-	gfSettings := chanState.Settings.Killers.GhostFace
+	gfSettings := chanState.EffectiveKillers().GhostFace
 	now := time.Now()
 
 	if chanState.Settings.Disabled {
 		return
 	}
 
-	if g.handleCommands(userMsg) {
-		return
-	}
-
 	user := chanState.UserMap[userMsg.Username]
 	diff := now.Sub(chanState.Date)
 
@@ -160,7 +168,7 @@ func (g *GhostFace) HandleMessage(userMsg db.Message) {
 		return
 	}
 
-	if userMsg.Username == userMsg.Channel || userMsg.IsMod || strings.Contains(userMsg.Username, "bot") || userMsg.Username == util.BotOwner {
+	if protection.Spectator(userMsg.Channel, userMsg.Username) || user.IsImmune() {
 		return
 	}
 
@@ -171,96 +179,141 @@ func (g *GhostFace) TimeRemaining(channel string) time.Duration {
 	return g.GetRemainingTime(channel, StalkTimerName)
 }
 
-func (g *GhostFace) handleCommands(userMsg db.Message) bool {
+func (g *GhostFace) Commands() []commands.Command {
+	return []commands.Command{
+		{
+			Name:         "tbag",
+			Aliases:      []string{"тибег"},
+			UserCooldown: 2 * time.Second,
+			Handler:      g.handleTbagCommand,
+		},
+		{
+			Name:         "reveal",
+			Aliases:      []string{"раскрыть"},
+			UserCooldown: 2 * time.Second,
+			Handler:      g.handleRevealCommand,
+		},
+	}
+}
+
+func (g *GhostFace) handleTbagCommand(call commands.Call) bool {
+	userMsg := call.Message
 	chanState := g.GetState(userMsg.Channel)
-	gfSettings := chanState.Settings.Killers.GhostFace
-	lang := chanState.Settings.Language
 	user := chanState.UserMap[userMsg.Username]
 
-	switch {
-	case strings.HasPrefix(userMsg.Text, "!killer"):
-		msg := g.GetLocalString(lang, "commands_gf", map[string]string{"STATS": fmt.Sprintf("https://leg.rofleksey.ru/#/stats/%s", userMsg.Channel)})
-		g.SendMessage(userMsg.Channel, msg)
+	if user.Health == "hooked" || user.Health == "dead" {
+		msg := g.GetUserString(userMsg.Channel, userMsg.Username, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
+		g.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
 		return true
+	}
 
-	case strings.HasPrefix(userMsg.Text, "!tbag"):
-		if user.Health == "hooked" || user.Health == "dead" {
-			msg := g.GetLocalString(lang, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
-			g.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+	msg := g.GetChannelString(userMsg.Channel, "gf_tbag", map[string]string{"USERNAME": userMsg.Username})
+	g.SendMessage(userMsg.Channel, msg)
+
+	g.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeTbag, Killer: g.Name()})
+	g.handleHit(userMsg.Channel, userMsg.Username)
+	return true
+}
 
-		msg := g.GetLocalString(lang, "gf_tbag", map[string]string{"USERNAME": userMsg.Username})
+func (g *GhostFace) handleRevealCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := g.GetState(userMsg.Channel)
+	gfSettings := chanState.EffectiveKillers().GhostFace
+	user := chanState.UserMap[userMsg.Username]
+
+	if user.Health == "hooked" || user.Health == "dead" || user.Marked {
+		msg := g.GetUserString(userMsg.Channel, userMsg.Username, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
+		g.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
+		return true
+	}
+
+	attempts := g.recordRevealAttempt(userMsg.Channel, userMsg.Username)
+
+	if rand.Float64() > gfSettings.RevealChance {
+		msg := g.GetChannelString(userMsg.Channel, "gf_reveal_fail", map[string]string{"USERNAME": userMsg.Username})
 		g.SendMessage(userMsg.Channel, msg)
 
 		g.handleHit(userMsg.Channel, userMsg.Username)
 		return true
+	}
 
-	case strings.HasPrefix(userMsg.Text, "!reveal"):
-		if user.Health == "hooked" || user.Health == "dead" || user.Marked {
-			msg := g.GetLocalString(lang, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
-			g.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+	msg := g.GetChannelString(userMsg.Channel, "gf_reveal", map[string]string{"USERNAME": userMsg.Username})
+	g.SendMessage(userMsg.Channel, msg)
 
-		if rand.Float64() > gfSettings.RevealChance {
-			msg := g.GetLocalString(lang, "gf_reveal_fail", map[string]string{"USERNAME": userMsg.Username})
-			g.SendMessage(userMsg.Channel, msg)
+	g.handleHit(userMsg.Channel, userMsg.Username)
 
-			g.handleHit(userMsg.Channel, userMsg.Username)
-			return true
-		}
+	chanState = g.GetState(userMsg.Channel)
+	if chanState.Killer != "ghostface" {
+		return true
+	}
 
-		msg := g.GetLocalString(lang, "gf_reveal", map[string]string{"USERNAME": userMsg.Username})
-		g.SendMessage(userMsg.Channel, msg)
+	var gfState db.GhostFaceState
+	if err := mapstructure.Decode(chanState.KillerState, &gfState); err != nil {
+		slog.Error("Failed to decode killer state",
+			slog.String("channel", userMsg.Channel),
+			slog.Any("error", err),
+		)
+	}
 
-		g.handleHit(userMsg.Channel, userMsg.Username)
+	g.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+		chanState.Killer = ""
+		chanState.KillerState = nil
+		chanState.Date = time.Now()
+		chanState.UserMap[userMsg.Username].Stats["stuns"]++
+		chanState.Stats["fail"]++
 
-		chanState := g.GetState(userMsg.Channel)
-		if chanState.Killer != "ghostface" {
-			return true
+		for u := range chanState.UserMap {
+			if !gfState.StalkedThisRound[u] {
+				chanState.UserMap[u].Marked = false
+			}
 		}
+	})
 
-		var gfState db.GhostFaceState
-		if err := mapstructure.Decode(chanState.KillerState, &gfState); err != nil {
-			slog.Error("Failed to decode killer state",
-				slog.String("channel", userMsg.Channel),
-				slog.Any("error", err),
-			)
-		}
+	var detail string
+	if attempts == 1 {
+		detail = "first_try"
+	}
 
-		g.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-			chanState.Killer = ""
-			chanState.KillerState = nil
-			chanState.Date = time.Now()
-			chanState.UserMap[userMsg.Username].Stats["stuns"]++
-			chanState.Stats["fail"]++
+	g.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeReveal, Killer: g.Name(), Detail: detail})
+	g.Emit(events.Event{Channel: userMsg.Channel, Type: events.TypeSessionEnd, Killer: g.Name()})
 
-			for u := range chanState.UserMap {
-				if !gfState.StalkedThisRound[u] {
-					chanState.UserMap[u].Marked = false
-				}
-			}
-		})
+	g.StopTimer(userMsg.Channel, StalkTimerName)
 
-		g.StopTimer(userMsg.Channel, StalkTimerName)
+	msg = g.GetChannelString(userMsg.Channel, "gf_revealed", map[string]string{"USERNAME": userMsg.Username})
+	g.SendMessage(userMsg.Channel, msg)
 
-		msg = g.GetLocalString(lang, "gf_revealed", map[string]string{"USERNAME": userMsg.Username})
-		g.SendMessage(userMsg.Channel, msg)
+	msg = g.GetChannelString(userMsg.Channel, "gf_go_away", map[string]string{"COUNT": fmt.Sprint(len(gfState.StalkedThisRound))})
+	g.SendMessage(userMsg.Channel, msg)
 
-		msg = g.GetLocalString(lang, "gf_go_away", map[string]string{"COUNT": fmt.Sprint(len(gfState.StalkedThisRound))})
-		g.SendMessage(userMsg.Channel, msg)
+	return true
+}
 
-		return true
+func (g *GhostFace) recordRevealAttempt(channel, username string) int {
+	chanState := g.GetState(channel)
+
+	var gfState db.GhostFaceState
+	if err := mapstructure.Decode(chanState.KillerState, &gfState); err != nil {
+		slog.Error("Failed to decode killer state",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+	}
+
+	if gfState.RevealAttempts == nil {
+		gfState.RevealAttempts = make(map[string]int)
 	}
+	gfState.RevealAttempts[username]++
 
-	return false
+	g.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.KillerState = gfState
+	})
+
+	return gfState.RevealAttempts[username]
 }
 
 func (g *GhostFace) handleHit(channel, username string) {
 	chanState := g.GetState(channel)
-	gfSettings := chanState.Settings.Killers.GhostFace
-	lang := chanState.Settings.Language
+	gfSettings := chanState.EffectiveKillers().GhostFace
 	now := time.Now()
 
 	var gfState db.GhostFaceState
@@ -310,13 +363,16 @@ func (g *GhostFace) handleHit(channel, username string) {
 		}
 	})
 
+	g.Emit(events.Event{Channel: channel, Username: username, Type: events.TypeHook, Killer: g.Name()})
+	g.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: g.Name()})
+
 	g.StopTimer(channel, StalkTimerName)
 	g.StopTimer(channel, username)
 	g.TimeoutUser(channel, username, gfSettings.HookBanTime, "")
 
-	msg := g.GetLocalString(lang, "gf_hit_dead", map[string]string{"USERNAME": username})
+	msg := g.GetChannelString(channel, "gf_hit_dead", map[string]string{"USERNAME": username})
 	g.SendMessage(channel, msg)
 
-	msg = g.GetLocalString(lang, "gf_go_away", map[string]string{"COUNT": fmt.Sprint(len(gfState.StalkedThisRound))})
+	msg = g.GetChannelString(channel, "gf_go_away", map[string]string{"COUNT": fmt.Sprint(len(gfState.StalkedThisRound))})
 	g.SendMessage(channel, msg)
 }
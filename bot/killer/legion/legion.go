@@ -1,18 +1,21 @@
 package legion
 
 import (
-	"fmt"
 	"github.com/mitchellh/mapstructure"
 	"github.com/samber/do"
+	"legion-bot-v2/bot/addons"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/protection"
 	"legion-bot-v2/db"
 	"legion-bot-v2/gpt"
 	"legion-bot-v2/twitch/chat"
-	"legion-bot-v2/util"
 	"legion-bot-v2/util/timers"
 	"log/slog"
 	"math/rand/v2"
+	"sort"
 	"strings"
 	"time"
 )
@@ -29,6 +32,8 @@ type Legion struct {
 	timers.Timers
 	i18n.Localiser
 	gpt.Gpt
+	events.Bus
+	commands.Registry
 }
 
 func (l *Legion) HandleWhisper(userMsg db.PartialMessage) {
@@ -40,6 +45,10 @@ func (l *Legion) Weight(channel string) int {
 	return chanState.Settings.Killers.Legion.Weight
 }
 
+func (l *Legion) Scopes() []string {
+	return []string{chat.ScopeBannedUsers}
+}
+
 func (l *Legion) FixSettings(chanState *db.ChannelState) bool {
 	if chanState.Settings.Killers.Legion != nil {
 		return false
@@ -60,124 +69,145 @@ func (l *Legion) Enabled(channel string) bool {
 }
 
 func New(di *do.Injector) *Legion {
-	return &Legion{
+	l := &Legion{
 		DB:        do.MustInvoke[db.DB](di),
 		Actions:   do.MustInvoke[chat.Actions](di),
 		Timers:    do.MustInvoke[timers.Timers](di),
 		Localiser: do.MustInvoke[i18n.Localiser](di),
 		Gpt:       do.MustInvoke[gpt.Gpt](di),
+		Bus:       do.MustInvoke[events.Bus](di),
+		Registry:  do.MustInvoke[commands.Registry](di),
+	}
+
+	catalog := do.MustInvoke[addons.Catalog](di)
+	catalog.OnEffect(addons.EffectMarkOnHit, events.TypeHit, l.markOnHit)
+	catalog.OnEffect(addons.EffectRevealDeepWoundedOnHit, events.TypeHit, l.revealOnHit)
+
+	return l
+}
+
+func (l *Legion) Commands() []commands.Command {
+	return []commands.Command{
+		{
+			Name:         "pallet",
+			Aliases:      []string{"палета", "паллета"},
+			UserCooldown: 2 * time.Second,
+			Handler:      l.handlePalletCommand,
+		},
+		{
+			Name:         "tbag",
+			Aliases:      []string{"тибег"},
+			UserCooldown: 2 * time.Second,
+			Handler:      l.handleTbagCommand,
+		},
+		{
+			Name:         "locker",
+			Aliases:      []string{"шкаф"},
+			UserCooldown: 2 * time.Second,
+			Handler:      l.handleLockerCommand,
+		},
 	}
 }
 
-func (l *Legion) handleCommands(userMsg db.Message) bool {
+func (l *Legion) handlePalletCommand(call commands.Call) bool {
+	userMsg := call.Message
 	chanState := l.GetState(userMsg.Channel)
-	legionSettings := chanState.Settings.Killers.Legion
-	lang := chanState.Settings.Language
+	legionSettings := chanState.EffectiveKillers().Legion
 	now := time.Now()
 	user := chanState.UserMap[userMsg.Username]
 
-	switch {
-	case strings.HasPrefix(userMsg.Text, "!killer"):
-		msg := l.GetLocalString(lang, "commands_legion", map[string]string{"STATS": fmt.Sprintf("https://leg.rofleksey.ru/#/stats/%s", userMsg.Channel)})
-		l.SendMessage(userMsg.Channel, msg)
-		return true
+	if user.Health == "hooked" || user.Health == "dead" {
+		msg := l.GetUserString(userMsg.Channel, userMsg.Username, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
+		l.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
 
-	case strings.HasPrefix(userMsg.Text, "!pallet"):
-		if user.Health == "hooked" || user.Health == "dead" {
-			msg := l.GetLocalString(lang, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
-
-			return true
-		}
+		return true
+	}
 
-		if chanState.Killer == "" || user.Health == "deep_wound" {
-			msg := l.GetLocalString(lang, "pallet_wasted", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
+	if chanState.Killer == "" || user.Health == "deep_wound" {
+		msg := l.GetChannelString(userMsg.Channel, "pallet_wasted", map[string]string{"USERNAME": userMsg.Username})
+		l.SendMessageWithPriority(userMsg.Channel, msg, chat.PriorityLow)
 
-			return true
-		}
+		return true
+	}
 
-		if rand.Float64() > legionSettings.PalletStunChance {
-			msg := l.GetLocalString(lang, "pallet_failed", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
+	if rand.Float64() > legionSettings.PalletStunChance {
+		msg := l.GetChannelString(userMsg.Channel, "pallet_failed", map[string]string{"USERNAME": userMsg.Username})
+		l.SendMessage(userMsg.Channel, msg)
 
-			l.handleHit(userMsg.Channel, userMsg.Username)
-			return true
-		}
+		l.handleHit(userMsg.Channel, userMsg.Username)
+		return true
+	}
 
-		l.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-			chanState.Killer = ""
-			chanState.KillerState = nil
-			chanState.Date = now
-			chanState.Stats["stuns"]++
-			chanState.UserMap[userMsg.Username].Stats["stuns"]++
-		})
+	l.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+		chanState.Killer = ""
+		chanState.KillerState = nil
+		chanState.Date = now
+		chanState.Stats["stuns"]++
+		chanState.UserMap[userMsg.Username].Stats["stuns"]++
+	})
 
-		l.StopTimer(userMsg.Channel, FrenzyTimerName)
+	l.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeStun, Killer: l.Name(), Detail: "pallet"})
+	l.Emit(events.Event{Channel: userMsg.Channel, Type: events.TypeSessionEnd, Killer: l.Name()})
 
-		msg := l.GetLocalString(lang, "pallet_success", map[string]string{"USERNAME": userMsg.Username})
-		l.SendMessage(userMsg.Channel, msg)
-		return true
+	l.StopTimer(userMsg.Channel, FrenzyTimerName)
 
-	case strings.HasPrefix(userMsg.Text, "!tbag"):
-		if user.Health == "hooked" || user.Health == "dead" {
-			msg := l.GetLocalString(lang, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+	msg := l.GetChannelString(userMsg.Channel, "pallet_success", map[string]string{"USERNAME": userMsg.Username})
+	l.SendMessage(userMsg.Channel, msg)
+	return true
+}
 
-		if chanState.Killer == "" || user.Health == "deep_wound" {
-			msg := l.GetLocalString(lang, "tbag_wasted", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
+func (l *Legion) handleTbagCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := l.GetState(userMsg.Channel)
+	user := chanState.UserMap[userMsg.Username]
 
-			return true
-		}
+	if user.Health == "hooked" || user.Health == "dead" {
+		msg := l.GetUserString(userMsg.Channel, userMsg.Username, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
+		l.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
+		return true
+	}
 
-		msg := l.GetLocalString(lang, "tbag_success", map[string]string{"USERNAME": userMsg.Username})
-		l.SendMessage(userMsg.Channel, msg)
+	if chanState.Killer == "" || user.Health == "deep_wound" {
+		msg := l.GetChannelString(userMsg.Channel, "tbag_wasted", map[string]string{"USERNAME": userMsg.Username})
+		l.SendMessageWithPriority(userMsg.Channel, msg, chat.PriorityLow)
 
-		l.handleHit(userMsg.Channel, userMsg.Username)
 		return true
+	}
 
-	case strings.HasPrefix(userMsg.Text, "!locker"):
-		if user.Health == "hooked" || user.Health == "dead" {
-			msg := l.GetLocalString(lang, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
-
-			return true
-		}
+	msg := l.GetChannelString(userMsg.Channel, "tbag_success", map[string]string{"USERNAME": userMsg.Username})
+	l.SendMessage(userMsg.Channel, msg)
 
-		if chanState.Killer == "" || user.Health == "deep_wound" {
-			msg := l.GetLocalString(lang, "locker_wasted", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
-			return true
-		}
+	l.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeTbag, Killer: l.Name()})
+	l.handleHit(userMsg.Channel, userMsg.Username)
+	return true
+}
 
-		if rand.Float64() > legionSettings.LockerStunChance {
-			msg := l.GetLocalString(lang, "locker_failed", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
+func (l *Legion) handleLockerCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := l.GetState(userMsg.Channel)
+	legionSettings := chanState.EffectiveKillers().Legion
+	now := time.Now()
+	user := chanState.UserMap[userMsg.Username]
 
-			if rand.Float64() > legionSettings.LockerGrabChance {
-				l.handleHit(userMsg.Channel, userMsg.Username)
-				return true
-			}
+	if user.Health == "hooked" || user.Health == "dead" {
+		msg := l.GetUserString(userMsg.Channel, userMsg.Username, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
+		l.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
 
-			l.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-				chanState.Killer = ""
-				chanState.KillerState = nil
-				chanState.Date = now
-				chanState.UserMap[userMsg.Username].Health = "hooked"
-				chanState.UserMap[userMsg.Username].Stats["hooks"]++
-				chanState.Stats["success"]++
-			})
+		return true
+	}
 
-			l.StopTimer(userMsg.Channel, FrenzyTimerName)
-			l.StopTimer(userMsg.Channel, userMsg.Username)
-			l.TimeoutUser(userMsg.Channel, userMsg.Username, legionSettings.HookBanTime, "")
+	if chanState.Killer == "" || user.Health == "deep_wound" {
+		msg := l.GetChannelString(userMsg.Channel, "locker_wasted", map[string]string{"USERNAME": userMsg.Username})
+		l.SendMessageWithPriority(userMsg.Channel, msg, chat.PriorityLow)
+		return true
+	}
 
-			msg = l.GetLocalString(lang, "locker_grab", map[string]string{"USERNAME": userMsg.Username})
-			l.SendMessage(userMsg.Channel, msg)
+	if rand.Float64() > legionSettings.LockerStunChance {
+		msg := l.GetChannelString(userMsg.Channel, "locker_failed", map[string]string{"USERNAME": userMsg.Username})
+		l.SendMessage(userMsg.Channel, msg)
 
+		if rand.Float64() > legionSettings.LockerGrabChance {
+			l.handleHit(userMsg.Channel, userMsg.Username)
 			return true
 		}
 
@@ -185,19 +215,41 @@ func (l *Legion) handleCommands(userMsg db.Message) bool {
 			chanState.Killer = ""
 			chanState.KillerState = nil
 			chanState.Date = now
-			chanState.Stats["stuns"]++
-			chanState.UserMap[userMsg.Username].Stats["stuns"]++
+			chanState.UserMap[userMsg.Username].Health = "hooked"
+			chanState.UserMap[userMsg.Username].Stats["hooks"]++
+			chanState.Stats["success"]++
 		})
 
+		l.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeHook, Killer: l.Name()})
+		l.Emit(events.Event{Channel: userMsg.Channel, Type: events.TypeSessionEnd, Killer: l.Name()})
+
 		l.StopTimer(userMsg.Channel, FrenzyTimerName)
+		l.StopTimer(userMsg.Channel, userMsg.Username)
+		l.TimeoutUser(userMsg.Channel, userMsg.Username, legionSettings.HookBanTime, "")
 
-		msg := l.GetLocalString(lang, "locker_success", map[string]string{"USERNAME": userMsg.Username})
+		msg = l.GetChannelString(userMsg.Channel, "locker_grab", map[string]string{"USERNAME": userMsg.Username})
 		l.SendMessage(userMsg.Channel, msg)
 
 		return true
 	}
 
-	return false
+	l.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+		chanState.Killer = ""
+		chanState.KillerState = nil
+		chanState.Date = now
+		chanState.Stats["stuns"]++
+		chanState.UserMap[userMsg.Username].Stats["stuns"]++
+	})
+
+	l.Emit(events.Event{Channel: userMsg.Channel, Username: userMsg.Username, Type: events.TypeStun, Killer: l.Name(), Detail: "locker"})
+	l.Emit(events.Event{Channel: userMsg.Channel, Type: events.TypeSessionEnd, Killer: l.Name()})
+
+	l.StopTimer(userMsg.Channel, FrenzyTimerName)
+
+	msg := l.GetChannelString(userMsg.Channel, "locker_success", map[string]string{"USERNAME": userMsg.Username})
+	l.SendMessage(userMsg.Channel, msg)
+
+	return true
 }
 
 func (l *Legion) Start(userMsg db.Message) {
@@ -210,18 +262,13 @@ func (l *Legion) TimeRemaining(channel string) time.Duration {
 
 func (l *Legion) HandleMessage(userMsg db.Message) {
 	chanState := l.GetState(userMsg.Channel)
-	legionSettings := chanState.Settings.Killers.Legion
-	lang := chanState.Settings.Language
+	legionSettings := chanState.EffectiveKillers().Legion
 	now := time.Now()
 
 	if chanState.Settings.Disabled {
 		return
 	}
 
-	if l.handleCommands(userMsg) {
-		return
-	}
-
 	user := chanState.UserMap[userMsg.Username]
 	diff := now.Sub(chanState.Date)
 
@@ -234,18 +281,18 @@ func (l *Legion) HandleMessage(userMsg db.Message) {
 	}
 
 	if user.Health == "hooked" {
-		msg := l.GetLocalString(lang, "on_hook_camp", map[string]string{"USERNAME": userMsg.Username})
-		l.SendMessage(userMsg.Channel, msg)
+		msg := l.GetChannelString(userMsg.Channel, "on_hook_camp", map[string]string{"USERNAME": userMsg.Username})
+		l.SendMessageWithPriority(userMsg.Channel, msg, chat.PriorityLow)
 		return
 	}
 
 	if user.Health == "dead" {
-		msg := l.GetLocalString(lang, "on_dead_camp", map[string]string{"USERNAME": userMsg.Username})
-		l.SendMessage(userMsg.Channel, msg)
+		msg := l.GetChannelString(userMsg.Channel, "on_dead_camp", map[string]string{"USERNAME": userMsg.Username})
+		l.SendMessageWithPriority(userMsg.Channel, msg, chat.PriorityLow)
 		return
 	}
 
-	if userMsg.Username == userMsg.Channel || userMsg.IsMod || strings.Contains(userMsg.Username, "bot") || userMsg.Username == util.BotOwner {
+	if protection.Spectator(userMsg.Channel, userMsg.Username) || user.IsImmune() {
 		return
 	}
 
@@ -256,8 +303,7 @@ func (l *Legion) startFrenzyTimer(channel string) {
 	l.StopTimer(channel, FrenzyTimerName)
 
 	chanState := l.GetState(channel)
-	legionSettings := chanState.Settings.Killers.Legion
-	lang := chanState.Settings.Language
+	legionSettings := chanState.EffectiveKillers().Legion
 
 	l.StartTimer(channel, FrenzyTimerName, legionSettings.FrenzyTimeout, func() {
 		l.UpdateState(channel, func(chanState *db.ChannelState) {
@@ -267,14 +313,15 @@ func (l *Legion) startFrenzyTimer(channel string) {
 			chanState.Stats["fail"]++
 		})
 
-		msg := l.GetLocalString(lang, "frenzy_timeout", nil)
+		l.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: l.Name()})
+
+		msg := l.GetChannelString(channel, "frenzy_timeout", nil)
 		l.SendMessage(channel, msg)
 	})
 }
 
 func (l *Legion) startFrenzy(channel string) {
 	startState := l.GetState(channel)
-	lang := startState.Settings.Language
 	now := time.Now()
 
 	if startState.Killer != "" {
@@ -293,7 +340,9 @@ func (l *Legion) startFrenzy(channel string) {
 		}
 	})
 
-	msg := l.GetLocalString(lang, "start_legion", nil)
+	l.RegisterCommands(channel, l.Name(), l.Commands()...)
+
+	msg := l.GetChannelString(channel, "start_legion", nil)
 	l.SendMessage(channel, msg)
 
 	l.startFrenzyTimer(channel)
@@ -305,7 +354,7 @@ func (l *Legion) startRecoverTimer(channel, username string) {
 	l.StopTimer(channel, username)
 
 	chanState := l.GetState(channel)
-	legionSettings := chanState.Settings.Killers.Legion
+	legionSettings := chanState.EffectiveKillers().Legion
 
 	l.StartTimer(channel, username, legionSettings.BleedOutBanTime, func() {
 		l.UpdateState(channel, func(chanState *db.ChannelState) {
@@ -318,8 +367,7 @@ func (l *Legion) startDeadTimer(channel, username string) {
 	l.StopTimer(channel, username)
 
 	chanState := l.GetState(channel)
-	legionSettings := chanState.Settings.Killers.Legion
-	lang := chanState.Settings.Language
+	legionSettings := chanState.EffectiveKillers().Legion
 
 	l.StartTimer(channel, username, legionSettings.DeepWoundTimeout, func() {
 		l.UpdateState(channel, func(chanState *db.ChannelState) {
@@ -331,7 +379,7 @@ func (l *Legion) startDeadTimer(channel, username string) {
 
 		l.TimeoutUser(channel, username, legionSettings.BleedOutBanTime, "")
 
-		msg := l.GetLocalString(lang, "on_dead", map[string]string{"USERNAME": username})
+		msg := l.GetChannelString(channel, "on_dead", map[string]string{"USERNAME": username})
 		l.SendMessage(channel, msg)
 
 		l.startRecoverTimer(channel, username)
@@ -340,8 +388,7 @@ func (l *Legion) startDeadTimer(channel, username string) {
 
 func (l *Legion) handleHit(channel, username string) {
 	chanState := l.GetState(channel)
-	legionSettings := chanState.Settings.Killers.Legion
-	lang := chanState.Settings.Language
+	legionSettings := chanState.EffectiveKillers().Legion
 	now := time.Now()
 
 	var legionState db.LegionState
@@ -368,15 +415,17 @@ func (l *Legion) handleHit(channel, username string) {
 			chanState.Stats["miss"]++
 		})
 
+		l.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: l.Name()})
+
 		l.StopTimer(channel, FrenzyTimerName)
 
-		msg := l.GetLocalString(lang, "on_frenzy_miss", map[string]string{"USERNAME": username})
+		msg := l.GetChannelString(channel, "on_frenzy_miss", map[string]string{"USERNAME": username})
 		l.SendMessage(channel, msg)
 
 		return
 	}
 
-	if legionState.HitCount == legionSettings.FatalHit-1 {
+	if legionState.HitCount == legionSettings.FatalHit-1 || legionState.Marked[username] {
 		l.UpdateState(channel, func(chanState *db.ChannelState) {
 			chanState.Killer = ""
 			chanState.KillerState = nil
@@ -386,11 +435,14 @@ func (l *Legion) handleHit(channel, username string) {
 			chanState.Stats["success"]++
 		})
 
+		l.Emit(events.Event{Channel: channel, Username: username, Type: events.TypeHook, Killer: l.Name()})
+		l.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: l.Name()})
+
 		l.StopTimer(channel, FrenzyTimerName)
 		l.StopTimer(channel, username)
 		l.TimeoutUser(channel, username, legionSettings.HookBanTime, "")
 
-		msg := l.GetLocalString(lang, "on_frenzy_hit_dead", map[string]string{"USERNAME": username})
+		msg := l.GetChannelString(channel, "on_frenzy_hit_dead", map[string]string{"USERNAME": username})
 		l.SendMessage(channel, msg)
 
 		return
@@ -409,16 +461,19 @@ func (l *Legion) handleHit(channel, username string) {
 			chanState.UserMap[username].Stats["bodyBlocks"]++
 		})
 
+		l.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: l.Name()})
+
 		l.StopTimer(channel, FrenzyTimerName)
 		l.startDeadTimer(channel, username)
 
-		msg := l.GetLocalString(lang, "on_frenzy_hit_deep_wound", map[string]string{"USERNAME": username})
+		msg := l.GetChannelString(channel, "on_frenzy_hit_deep_wound", map[string]string{"USERNAME": username})
 		l.SendMessage(channel, msg)
 
 		return
 	}
 
 	legionState.HitCount++
+
 	l.UpdateState(channel, func(chanState *db.ChannelState) {
 		chanState.KillerState = legionState
 		chanState.Stats["hits"]++
@@ -431,12 +486,57 @@ func (l *Legion) handleHit(channel, username string) {
 	l.startFrenzyTimer(channel)
 
 	if legionState.HitCount == legionSettings.FatalHit-1 {
-		msg := l.GetLocalString(lang, "on_frenzy_hit_prefinal", map[string]string{"USERNAME": username})
+		msg := l.GetChannelString(channel, "on_frenzy_hit_prefinal", map[string]string{"USERNAME": username})
 		l.SendMessage(channel, msg)
 	} else {
-		msg := l.GetLocalString(lang, "on_frenzy_hit", map[string]string{"USERNAME": username})
+		msg := l.GetChannelString(channel, "on_frenzy_hit", map[string]string{"USERNAME": username})
 		l.SendMessage(channel, msg)
 	}
 
-	return
+	l.Emit(events.Event{Channel: channel, Username: username, Type: events.TypeHit, Killer: l.Name()})
+}
+
+// markOnHit makes the next hit of the survivor hook them
+func (l *Legion) markOnHit(event events.Event) {
+	l.UpdateState(event.Channel, func(chanState *db.ChannelState) {
+		var legionState db.LegionState
+		if err := mapstructure.Decode(chanState.KillerState, &legionState); err != nil {
+			slog.Error("Failed to decode killer state",
+				slog.String("channel", event.Channel),
+				slog.Any("error", err),
+			)
+			return
+		}
+
+		if legionState.Marked == nil {
+			legionState.Marked = make(map[string]bool)
+		}
+		legionState.Marked[event.Username] = true
+
+		chanState.KillerState = legionState
+	})
+
+	msg := l.GetChannelString(event.Channel, "legion_hit_marked", map[string]string{"USERNAME": event.Username})
+	l.SendMessage(event.Channel, msg)
+}
+
+// revealOnHit names every deep-wounded survivor
+func (l *Legion) revealOnHit(event events.Event) {
+	chanState := l.GetState(event.Channel)
+
+	var usernames []string
+	for username, user := range chanState.UserMap {
+		if user.Health == "deep_wound" {
+			usernames = append(usernames, "@"+username)
+		}
+	}
+
+	if len(usernames) == 0 {
+		return
+	}
+
+	sort.Strings(usernames)
+
+	msg := l.GetChannelString(event.Channel, "legion_deep_wounded_reveal", map[string]string{"USERS": strings.Join(usernames, ", ")})
+	l.SendMessage(event.Channel, msg)
 }
@@ -1,16 +1,17 @@
 package pinhead
 
 import (
-	"fmt"
 	"github.com/elliotchance/pie/v2"
 	"github.com/mitchellh/mapstructure"
 	"github.com/samber/do"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/protection"
 	"legion-bot-v2/db"
 	"legion-bot-v2/gpt"
 	"legion-bot-v2/twitch/chat"
-	"legion-bot-v2/util"
 	"legion-bot-v2/util/timers"
 	"log/slog"
 	"math/rand/v2"
@@ -30,6 +31,8 @@ type Pinhead struct {
 	timers.Timers
 	i18n.Localiser
 	gpt.Gpt
+	events.Bus
+	commands.Registry
 }
 
 func New(di *do.Injector) *Pinhead {
@@ -39,6 +42,8 @@ func New(di *do.Injector) *Pinhead {
 		Timers:    do.MustInvoke[timers.Timers](di),
 		Localiser: do.MustInvoke[i18n.Localiser](di),
 		Gpt:       do.MustInvoke[gpt.Gpt](di),
+		Bus:       do.MustInvoke[events.Bus](di),
+		Registry:  do.MustInvoke[commands.Registry](di),
 	}
 }
 
@@ -51,6 +56,10 @@ func (p *Pinhead) Weight(channel string) int {
 	return chanState.Settings.Killers.Pinhead.Weight
 }
 
+func (p *Pinhead) Scopes() []string {
+	return []string{chat.ScopeBannedUsers}
+}
+
 func (p *Pinhead) Enabled(channel string) bool {
 	chanState := p.GetState(channel)
 	return chanState.Settings.Killers.Pinhead.Enabled
@@ -74,7 +83,7 @@ func (p *Pinhead) startRecoverTimer(channel, username string) {
 	p.StopTimer(channel, username)
 
 	chanState := p.GetState(channel)
-	pinheadSettings := chanState.Settings.Killers.Pinhead
+	pinheadSettings := chanState.EffectiveKillers().Pinhead
 
 	p.StartTimer(channel, username, pinheadSettings.BleedOutBanTime, func() {
 		p.UpdateState(channel, func(chanState *db.ChannelState) {
@@ -87,8 +96,7 @@ func (p *Pinhead) startDeadTimer(channel, username string) {
 	p.StopTimer(channel, username)
 
 	chanState := p.GetState(channel)
-	pinheadSettings := chanState.Settings.Killers.Pinhead
-	lang := chanState.Settings.Language
+	pinheadSettings := chanState.EffectiveKillers().Pinhead
 
 	p.StartTimer(channel, username, pinheadSettings.DeepWoundTimeout, func() {
 		p.UpdateState(channel, func(chanState *db.ChannelState) {
@@ -100,7 +108,7 @@ func (p *Pinhead) startDeadTimer(channel, username string) {
 
 		p.TimeoutUser(channel, username, pinheadSettings.BleedOutBanTime, "")
 
-		msg := p.GetLocalString(lang, "on_dead", map[string]string{"USERNAME": username})
+		msg := p.GetChannelString(channel, "on_dead", map[string]string{"USERNAME": username})
 		p.SendMessage(channel, msg)
 
 		p.startRecoverTimer(channel, username)
@@ -111,13 +119,18 @@ func (p *Pinhead) startBoxTimer(channel string) {
 	p.StopTimer(channel, BoxTimerName)
 
 	chanState := p.GetState(channel)
-	pinheadSettings := chanState.Settings.Killers.Pinhead
-	lang := chanState.Settings.Language
+	pinheadSettings := chanState.EffectiveKillers().Pinhead
 
 	p.StartTimer(channel, BoxTimerName, pinheadSettings.Timeout, func() {
+		boxState := p.GetState(channel)
+
 		viewerList := p.GetViewerList(channel)
 		viewerList = pie.Filter(viewerList, func(s string) bool {
-			return s != util.BotUsername && s != util.BotOwner && s != channel
+			if user, ok := boxState.UserMap[s]; ok && user.IsImmune() {
+				return false
+			}
+
+			return !protection.Spectator(channel, s)
 		})
 
 		rand.Shuffle(len(viewerList), func(i, j int) {
@@ -130,6 +143,10 @@ func (p *Pinhead) startBoxTimer(channel string) {
 					break
 				}
 
+				if _, ok := chanState.UserMap[viewer]; !ok {
+					chanState.UserMap[viewer] = db.NewUser()
+				}
+
 				chanState.Stats["hits"]++
 				chanState.UserMap[viewer].Health = "deep_wound"
 				chanState.UserMap[viewer].Stats["hits"]++
@@ -143,15 +160,16 @@ func (p *Pinhead) startBoxTimer(channel string) {
 			chanState.Stats["success"]++
 		})
 
-		msg := p.GetLocalString(lang, "pinhead_success", map[string]string{})
+		p.Emit(events.Event{Channel: channel, Type: events.TypeSessionEnd, Killer: p.Name()})
+
+		msg := p.GetChannelString(channel, "pinhead_success", map[string]string{})
 		p.SendMessage(channel, msg)
 	})
 }
 
 func (p *Pinhead) startBox(channel string) {
 	startState := p.GetState(channel)
-	lang := startState.Settings.Language
-	pinheadSettings := startState.Settings.Killers.Pinhead
+	pinheadSettings := startState.EffectiveKillers().Pinhead
 	now := time.Now()
 
 	if startState.Killer != "" {
@@ -179,11 +197,13 @@ func (p *Pinhead) startBox(channel string) {
 		channelState.Stats["total"]++
 	})
 
+	p.RegisterCommands(channel, p.Name(), p.Commands()...)
+
 	if pinheadSettings.ShowTopic {
-		msg := p.GetLocalString(lang, "start_pinhead", map[string]string{"TOPIC": genRes.Topic})
+		msg := p.GetChannelString(channel, "start_pinhead", map[string]string{"TOPIC": genRes.Topic})
 		p.SendMessage(channel, msg)
 	} else {
-		msg := p.GetLocalString(lang, "start_pinhead_secret", nil)
+		msg := p.GetChannelString(channel, "start_pinhead_secret", nil)
 		p.SendMessage(channel, msg)
 	}
 
@@ -200,97 +220,95 @@ func (p *Pinhead) Start(userMsg db.Message) {
 	p.startBox(userMsg.Channel)
 }
 
-func (p *Pinhead) HandleMessage(userMsg db.Message) {
-	chanState := p.GetState(userMsg.Channel)
+func (p *Pinhead) HandleMessage(_ db.Message) {
 
-	if chanState.Settings.Disabled {
-		return
-	}
-
-	if p.handleCommands(userMsg) {
-		return
-	}
 }
 
 func (p *Pinhead) TimeRemaining(channel string) time.Duration {
 	return p.GetRemainingTime(channel, BoxTimerName)
 }
 
-func (p *Pinhead) handleCommands(userMsg db.Message) bool {
+func (p *Pinhead) Commands() []commands.Command {
+	return []commands.Command{
+		{
+			Name:           "solve",
+			Aliases:        []string{"решить"},
+			GlobalCooldown: 2 * time.Second,
+			UserCooldown:   5 * time.Second,
+			Handler:        p.handleSolveCommand,
+		},
+	}
+}
+
+func (p *Pinhead) handleSolveCommand(call commands.Call) bool {
+	userMsg := call.Message
 	chanState := p.GetState(userMsg.Channel)
 	lang := chanState.Settings.Language
 
-	switch {
-	case strings.HasPrefix(userMsg.Text, "!killer"):
-		msg := p.GetLocalString(lang, "commands_pinhead", map[string]string{"STATS": fmt.Sprintf("https://leg.rofleksey.ru/#/stats/%s", userMsg.Channel)})
-		p.SendMessage(userMsg.Channel, msg)
+	question := strings.ToLower(strings.ReplaceAll(call.Rest(), "@", ""))
+
+	var pinheadState db.PinheadState
+	if err := mapstructure.Decode(chanState.KillerState, &pinheadState); err != nil {
+		slog.Error("Failed to decode killer state",
+			slog.String("channel", userMsg.Channel),
+			slog.Any("error", err),
+		)
+	}
+
+	res, err := p.GuessWord(lang, pinheadState.Word, question)
+	if err != nil {
+		slog.Error("Failed to guess word",
+			slog.String("channel", userMsg.Channel),
+			slog.String("word", pinheadState.Word),
+			slog.String("question", question),
+			slog.Any("error", err),
+		)
+	}
+	if err != nil {
+		slog.Error("Failed to guess word",
+			slog.String("channel", userMsg.Channel),
+			slog.String("word", pinheadState.Word),
+			slog.String("question", question),
+			slog.Any("error", err),
+		)
+	}
+
+	switch res {
+	case GuessResultOK:
+		p.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+			chanState.Killer = ""
+			chanState.KillerState = nil
+			chanState.Date = time.Now()
+			chanState.Stats["fail"]++
+		})
+
+		p.Emit(events.Event{Channel: userMsg.Channel, Type: events.TypeSessionEnd, Killer: p.Name()})
+
+		msg := p.GetChannelString(userMsg.Channel, "pinhead_failed", map[string]string{"USERNAME": userMsg.Username, "WORD": pinheadState.Word})
+		p.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
+
 		return true
-	case strings.HasPrefix(userMsg.Text, "!solve"):
-		question := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.ReplaceAll(userMsg.Text, "@", ""), "!solve")))
-
-		var pinheadState db.PinheadState
-		if err := mapstructure.Decode(chanState.KillerState, &pinheadState); err != nil {
-			slog.Error("Failed to decode killer state",
-				slog.String("channel", userMsg.Channel),
-				slog.Any("error", err),
-			)
-		}
+	case GuessResultYes:
+		msg := p.GetChannelString(userMsg.Channel, "pinhead_yes", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
+		p.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
 
-		res, err := p.GuessWord(lang, pinheadState.Word, question)
-		if err != nil {
-			slog.Error("Failed to guess word",
-				slog.String("channel", userMsg.Channel),
-				slog.String("word", pinheadState.Word),
-				slog.String("question", question),
-				slog.Any("error", err),
-			)
-		}
-		if err != nil {
-			slog.Error("Failed to guess word",
-				slog.String("channel", userMsg.Channel),
-				slog.String("word", pinheadState.Word),
-				slog.String("question", question),
-				slog.Any("error", err),
-			)
-		}
+		return true
+	case GuessResultNo:
+		msg := p.GetChannelString(userMsg.Channel, "pinhead_no", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
+		p.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
 
-		switch res {
-		case GuessResultOK:
-			p.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
-				chanState.Killer = ""
-				chanState.KillerState = nil
-				chanState.Date = time.Now()
-				chanState.Stats["fail"]++
-			})
-
-			msg := p.GetLocalString(lang, "pinhead_failure", map[string]string{"USERNAME": userMsg.Username, "WORD": pinheadState.Word})
-			p.SendMessage(userMsg.Channel, msg)
-
-			return true
-		case GuessResultYes:
-			msg := p.GetLocalString(lang, "pinhead_yes", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
-			p.SendMessage(userMsg.Channel, msg)
-
-			return true
-		case GuessResultNo:
-			msg := p.GetLocalString(lang, "pinhead_no", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
-			p.SendMessage(userMsg.Channel, msg)
-
-			return true
-		case GuessResultMaybe:
-			msg := p.GetLocalString(lang, "pinhead_maybe", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
-			p.SendMessage(userMsg.Channel, msg)
-
-			return true
-		case GuessResultInvalid:
-			msg := p.GetLocalString(lang, "pinhead_invalid", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
-			p.SendMessage(userMsg.Channel, msg)
-
-			return true
-		}
+		return true
+	case GuessResultMaybe:
+		msg := p.GetChannelString(userMsg.Channel, "pinhead_maybe", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
+		p.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
+
+		return true
+	case GuessResultInvalid:
+		msg := p.GetChannelString(userMsg.Channel, "pinhead_invalid", map[string]string{"QUESTION": question, "USERNAME": userMsg.Username})
+		p.ReplyMessage(userMsg.Channel, chanState.Settings.ReplyParent(userMsg), msg)
 
 		return true
 	}
 
-	return false
+	return true
 }
@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/db"
+	"slices"
+	"strings"
+)
+
+// handleLangCommand lets a viewer pick the language of the replies aimed at them,
+// channel-wide announcements keep using the channel language.
+func (b *Bot) handleLangCommand(call commands.Call) bool {
+	userMsg := call.Message
+	languages := b.Languages()
+	lang := strings.ToLower(call.Rest())
+
+	switch {
+	case lang == "":
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "lang_current", map[string]string{
+			"USERNAME":  userMsg.Username,
+			"LANGUAGES": strings.Join(languages, ", "),
+			"LANG":      b.UserLanguage(userMsg.Channel, userMsg.Username),
+		})
+		b.reply(userMsg, msg)
+		return true
+
+	case lang == "reset":
+		b.setUserLanguage(userMsg.Channel, userMsg.Username, "")
+
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "lang_reset", map[string]string{"USERNAME": userMsg.Username})
+		b.reply(userMsg, msg)
+		return true
+
+	case !slices.Contains(languages, lang):
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "lang_unknown", map[string]string{
+			"USERNAME":  userMsg.Username,
+			"LANGUAGES": strings.Join(languages, ", "),
+		})
+		b.reply(userMsg, msg)
+		return true
+	}
+
+	b.setUserLanguage(userMsg.Channel, userMsg.Username, lang)
+
+	msg := b.GetUserString(userMsg.Channel, userMsg.Username, "lang_set", map[string]string{"USERNAME": userMsg.Username})
+	b.reply(userMsg, msg)
+
+	return true
+}
+
+func (b *Bot) setUserLanguage(channel, username, lang string) {
+	b.UpdateState(channel, func(chanState *db.ChannelState) {
+		if chanState.UserMap[username] == nil {
+			chanState.UserMap[username] = db.NewUser()
+		}
+		chanState.UserMap[username].Language = lang
+	})
+}
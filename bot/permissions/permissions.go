@@ -0,0 +1,140 @@
+package permissions
+
+import (
+	"errors"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// errorTTL is how long a failed Helix check lets the killers run before it is retried
+const errorTTL = time.Minute
+
+var ErrNotModerator = errors.New("the bot is not a moderator of the channel")
+
+// MissingScopesError lists the scopes a killer needs that the bot token doesn't have
+type MissingScopesError struct {
+	Scopes []string
+}
+
+func (e *MissingScopesError) Error() string {
+	return "the bot token lacks " + strings.Join(e.Scopes, ", ")
+}
+
+type Checker interface {
+	// Check returns why the killer can't run in the channel, or nil if it can.
+	// Failed Helix requests don't stop the killers, they are only logged.
+	Check(channel string, k killer.Killer) error
+	// Refresh drops the cached moderator status of the channel and the token scopes and checks them again
+	Refresh(channel string) error
+}
+
+var _ Checker = (*Impl)(nil)
+
+type Impl struct {
+	chat.Actions
+
+	moderatorCache *ttlcache.Cache[string, bool]     // channel -> bot is a moderator
+	scopesCache    *ttlcache.Cache[string, []string] // always the same key, the bot has a single token
+}
+
+func New(di *do.Injector) Checker {
+	moderatorCache := ttlcache.New(
+		ttlcache.WithTTL[string, bool](5 * time.Minute),
+	)
+	go moderatorCache.Start()
+
+	scopesCache := ttlcache.New(
+		ttlcache.WithTTL[string, []string](30 * time.Minute),
+	)
+	go scopesCache.Start()
+
+	return &Impl{
+		Actions:        do.MustInvoke[chat.Actions](di),
+		moderatorCache: moderatorCache,
+		scopesCache:    scopesCache,
+	}
+}
+
+func (c *Impl) Check(channel string, k killer.Killer) error {
+	if !c.isModerator(channel) {
+		return ErrNotModerator
+	}
+
+	scopes, ok := c.scopes()
+	if !ok {
+		return nil
+	}
+
+	var missing []string
+	for _, scope := range k.Scopes() {
+		if !slices.Contains(scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingScopesError{Scopes: missing}
+	}
+
+	return nil
+}
+
+func (c *Impl) Refresh(channel string) error {
+	c.moderatorCache.Delete(channel)
+	c.scopesCache.DeleteAll()
+
+	if !c.isModerator(channel) {
+		slog.Warn("Bot is not a moderator",
+			slog.String("channel", channel),
+		)
+		return ErrNotModerator
+	}
+
+	return nil
+}
+
+func (c *Impl) isModerator(channel string) bool {
+	if item := c.moderatorCache.Get(channel); item != nil {
+		return item.Value()
+	}
+
+	isModerator, err := c.IsBotModerator(channel)
+	if err != nil {
+		slog.Error("Failed to check moderator status",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		c.moderatorCache.Set(channel, true, errorTTL)
+		return true
+	}
+
+	c.moderatorCache.Set(channel, isModerator, ttlcache.DefaultTTL)
+
+	return isModerator
+}
+
+// scopes returns the scopes of the bot token, ok is false if they are unknown
+func (c *Impl) scopes() ([]string, bool) {
+	if item := c.scopesCache.Get(""); item != nil {
+		return item.Value(), item.Value() != nil
+	}
+
+	scopes, err := c.GetTokenScopes()
+	if err != nil {
+		slog.Error("Failed to get token scopes",
+			slog.Any("error", err),
+		)
+		c.scopesCache.Set("", nil, errorTTL)
+		return nil, false
+	}
+
+	c.scopesCache.Set("", scopes, ttlcache.DefaultTTL)
+
+	return scopes, true
+}
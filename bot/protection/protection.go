@@ -0,0 +1,106 @@
+package protection
+
+import (
+	"github.com/jellydator/ttlcache/v3"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"legion-bot-v2/util"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Spectator reports whether the user never takes part in the game: the streamer and the bots.
+func Spectator(channel, username string) bool {
+	return username == channel || username == util.BotUsername || strings.Contains(username, "bot")
+}
+
+var _ chat.Actions = (*Guard)(nil)
+
+// Guard applies the protection policy of the channel to every timeout.
+// Protected users keep playing, but their punishments are only announced in the chat.
+type Guard struct {
+	chat.Actions
+	database db.DB
+
+	followCache *ttlcache.Cache[string, time.Time] // channel/username -> followed at
+}
+
+func NewGuard(actions chat.Actions, database db.DB) *Guard {
+	followCache := ttlcache.New(
+		ttlcache.WithTTL[string, time.Time](time.Hour),
+	)
+	go followCache.Start()
+
+	return &Guard{
+		Actions:     actions,
+		database:    database,
+		followCache: followCache,
+	}
+}
+
+func (g *Guard) TimeoutUser(channel, username string, duration time.Duration, reason string) {
+	if g.IsProtected(channel, username) {
+		slog.Info("Skipping timeout of a protected user",
+			slog.String("channel", channel),
+			slog.String("username", username),
+			slog.Duration("duration", duration),
+		)
+		return
+	}
+
+	g.Actions.TimeoutUser(channel, username, duration, reason)
+}
+
+// IsProtected reports whether the user must not be timed out in the channel.
+func (g *Guard) IsProtected(channel, username string) bool {
+	if Spectator(channel, username) || username == util.BotOwner {
+		return true
+	}
+
+	chanState := g.database.GetState(channel)
+	policy := chanState.Settings.Protection
+	if policy == nil {
+		policy = db.DefaultProtectionSettings()
+	}
+
+	if slices.Contains(policy.Users, username) {
+		return true
+	}
+
+	if user, ok := chanState.UserMap[username]; ok {
+		roles := user.Roles
+
+		switch {
+		case roles.IsMod && policy.Mods:
+			return true
+		case roles.IsVIP && policy.VIPs:
+			return true
+		case policy.MinSubTier > 0 && roles.SubTier >= policy.MinSubTier:
+			return true
+		}
+	}
+
+	if policy.FollowedFor > 0 {
+		followedAt := g.followedAt(channel, username)
+		if !followedAt.IsZero() && time.Since(followedAt) >= policy.FollowedFor {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Guard) followedAt(channel, username string) time.Time {
+	key := channel + "/" + username
+
+	if item := g.followCache.Get(key); item != nil {
+		return item.Value()
+	}
+
+	followedAt := g.GetFollowedAt(channel, username)
+	g.followCache.Set(key, followedAt, ttlcache.DefaultTTL)
+
+	return followedAt
+}
@@ -0,0 +1,186 @@
+package punishments
+
+import (
+	"errors"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrEntryNotFound  = errors.New("punishment not found")
+	ErrNotOutstanding = errors.New("punishment is already over or reverted")
+)
+
+// Reasons of the reverts made by the bot itself
+const (
+	ReasonDashboard = "dashboard"
+	ReasonPause     = "legiontimeout"
+	ReasonDisabled  = "disabled"
+	ReasonStartup   = "startup"
+)
+
+type Tracker interface {
+	// Punishments returns every moderation action the bot took in the channel, oldest first
+	Punishments(channel string) []db.Punishment
+	// Outstanding returns the running timeouts and the emote-only mode if the bot turned it on
+	Outstanding(channel string) []db.Punishment
+	// Revert lifts an outstanding timeout or turns off the emote-only mode and records it
+	Revert(channel string, id uint64, reason string) (db.Punishment, error)
+	// Reconcile reverts all outstanding punishments of the channel
+	Reconcile(channel, reason string)
+}
+
+var (
+	_ Tracker      = (*Impl)(nil)
+	_ chat.Actions = (*Impl)(nil)
+)
+
+// Impl records the moderation actions passed to the chat, so they can be undone
+// when the game they belong to is gone, e.g. after a restart in the middle of the Dredge.
+type Impl struct {
+	chat.Actions
+	database db.DB
+}
+
+func New(actions chat.Actions, database db.DB) *Impl {
+	return &Impl{
+		Actions:  actions,
+		database: database,
+	}
+}
+
+func (l *Impl) TimeoutUser(channel, username string, duration time.Duration, reason string) {
+	l.Actions.TimeoutUser(channel, username, duration, reason)
+	l.database.AppendPunishment(channel, db.Punishment{
+		Kind:     db.PunishmentTimeout,
+		Username: username,
+		Duration: duration,
+		Reason:   reason,
+	})
+}
+
+func (l *Impl) UnbanUser(channel, username string) {
+	l.Actions.UnbanUser(channel, username)
+	l.database.AppendPunishment(channel, db.Punishment{
+		Kind:     db.PunishmentUnban,
+		Username: username,
+	})
+}
+
+func (l *Impl) DeleteMessage(channel, id string) {
+	l.Actions.DeleteMessage(channel, id)
+	l.database.AppendPunishment(channel, db.Punishment{
+		Kind:      db.PunishmentDelete,
+		MessageID: id,
+	})
+}
+
+func (l *Impl) SetEmoteMode(channel string, enabled bool) {
+	l.Actions.SetEmoteMode(channel, enabled)
+
+	kind := db.PunishmentEmoteOff
+	if enabled {
+		kind = db.PunishmentEmoteOn
+	}
+	l.database.AppendPunishment(channel, db.Punishment{Kind: kind})
+}
+
+func (l *Impl) Punishments(channel string) []db.Punishment {
+	return l.database.GetPunishments(channel)
+}
+
+func (l *Impl) Outstanding(channel string) []db.Punishment {
+	return outstanding(l.database.GetPunishments(channel), time.Now())
+}
+
+func (l *Impl) Revert(channel string, id uint64, reason string) (db.Punishment, error) {
+	entries := l.database.GetPunishments(channel)
+
+	found := false
+	for _, entry := range entries {
+		if entry.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return db.Punishment{}, ErrEntryNotFound
+	}
+
+	for _, entry := range outstanding(entries, time.Now()) {
+		if entry.ID == id {
+			return l.revert(channel, entry, reason)
+		}
+	}
+
+	return db.Punishment{}, ErrNotOutstanding
+}
+
+func (l *Impl) Reconcile(channel, reason string) {
+	for _, entry := range outstanding(l.database.GetPunishments(channel), time.Now()) {
+		l.revert(channel, entry, reason)
+	}
+}
+
+func (l *Impl) revert(channel string, entry db.Punishment, reason string) (db.Punishment, error) {
+	slog.Info("Reverting punishment",
+		slog.String("channel", channel),
+		slog.String("kind", string(entry.Kind)),
+		slog.String("username", entry.Username),
+		slog.String("reason", reason),
+	)
+
+	revert := db.Punishment{
+		Username: entry.Username,
+		Reason:   reason,
+		RevertOf: entry.ID,
+	}
+
+	// the wrapped actions are called directly, so the revert is recorded only once
+	switch entry.Kind {
+	case db.PunishmentTimeout:
+		l.Actions.UnbanUser(channel, entry.Username)
+		revert.Kind = db.PunishmentUnban
+	case db.PunishmentEmoteOn:
+		l.Actions.SetEmoteMode(channel, false)
+		revert.Kind = db.PunishmentEmoteOff
+	default:
+		return db.Punishment{}, ErrNotOutstanding
+	}
+
+	return l.database.AppendPunishment(channel, revert)
+}
+
+// outstanding returns the timeouts still running and the emote-only mode if it is still on
+func outstanding(entries []db.Punishment, now time.Time) []db.Punishment {
+	timeouts := make(map[string]db.Punishment)
+	var emoteMode *db.Punishment
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case db.PunishmentTimeout:
+			timeouts[entry.Username] = entry
+		case db.PunishmentUnban:
+			delete(timeouts, entry.Username)
+		case db.PunishmentEmoteOn:
+			emoteMode = &entry
+		case db.PunishmentEmoteOff:
+			emoteMode = nil
+		}
+	}
+
+	var result []db.Punishment
+	for _, entry := range timeouts {
+		if entry.Expires().After(now) {
+			result = append(result, entry)
+		}
+	}
+
+	if emoteMode != nil {
+		result = append(result, *emoteMode)
+	}
+
+	return result
+}
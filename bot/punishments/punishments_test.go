@@ -0,0 +1,70 @@
+package punishments
+
+import (
+	"github.com/stretchr/testify/require"
+	"legion-bot-v2/db"
+	"slices"
+	"testing"
+	"time"
+)
+
+func ids(entries []db.Punishment) []uint64 {
+	result := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.ID)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func TestOutstandingTimeoutThenUnban(t *testing.T) {
+	now := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	entries := []db.Punishment{
+		{ID: 1, Time: now.Add(-time.Minute), Kind: db.PunishmentTimeout, Username: "alice", Duration: 10 * time.Minute},
+		{ID: 2, Time: now.Add(-time.Minute), Kind: db.PunishmentTimeout, Username: "bob", Duration: 10 * time.Minute},
+		{ID: 3, Time: now.Add(-30 * time.Second), Kind: db.PunishmentUnban, Username: "alice", RevertOf: 1},
+	}
+
+	require.Equal(t, []uint64{2}, ids(outstanding(entries, now)))
+}
+
+func TestOutstandingTimeoutAfterUnban(t *testing.T) {
+	now := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	entries := []db.Punishment{
+		{ID: 1, Time: now.Add(-2 * time.Minute), Kind: db.PunishmentTimeout, Username: "alice", Duration: 10 * time.Minute},
+		{ID: 2, Time: now.Add(-time.Minute), Kind: db.PunishmentUnban, Username: "alice", RevertOf: 1},
+		{ID: 3, Time: now.Add(-30 * time.Second), Kind: db.PunishmentTimeout, Username: "alice", Duration: 10 * time.Minute},
+	}
+
+	require.Equal(t, []uint64{3}, ids(outstanding(entries, now)))
+}
+
+func TestOutstandingExpired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	entries := []db.Punishment{
+		{ID: 1, Time: now.Add(-time.Hour), Kind: db.PunishmentTimeout, Username: "alice", Duration: 10 * time.Minute},
+		{ID: 2, Time: now.Add(-10 * time.Minute), Kind: db.PunishmentTimeout, Username: "bob", Duration: 10 * time.Minute},
+		{ID: 3, Time: now.Add(-time.Minute), Kind: db.PunishmentTimeout, Username: "carol", Duration: 10 * time.Minute},
+		{ID: 4, Time: now.Add(-time.Minute), Kind: db.PunishmentDelete, MessageID: "message"},
+	}
+
+	require.Equal(t, []uint64{3}, ids(outstanding(entries, now)))
+}
+
+func TestOutstandingEmoteMode(t *testing.T) {
+	now := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	entries := []db.Punishment{
+		{ID: 1, Time: now.Add(-3 * time.Minute), Kind: db.PunishmentEmoteOn},
+		{ID: 2, Time: now.Add(-2 * time.Minute), Kind: db.PunishmentEmoteOff},
+	}
+
+	require.Empty(t, outstanding(entries, now))
+
+	entries = append(entries, db.Punishment{ID: 3, Time: now.Add(-time.Minute), Kind: db.PunishmentEmoteOn})
+
+	require.Equal(t, []uint64{3}, ids(outstanding(entries, now)))
+}
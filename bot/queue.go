@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"legion-bot-v2/bot/commands"
+	"slices"
+	"strings"
+)
+
+func (b *Bot) handleQueueCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
+	queue := slices.Clone(chanState.Scheduler.Queue)
+
+	args := strings.Fields(strings.ToLower(call.Rest()))
+
+	if len(args) > 0 && commands.PermissionMod.Allows(userMsg) {
+		switch {
+		case args[0] == "add" && len(args) > 1:
+			queue = append(queue, args[1])
+
+		case args[0] == "remove" && len(args) > 1:
+			i := slices.Index(queue, args[1])
+			if i < 0 {
+				msg := b.GetUserString(userMsg.Channel, userMsg.Username, "queue_unknown_killer", map[string]string{"USERNAME": userMsg.Username})
+				b.reply(userMsg, msg)
+				return true
+			}
+			queue = slices.Delete(queue, i, i+1)
+
+		case args[0] == "clear":
+			queue = nil
+
+		default:
+			msg := b.GetUserString(userMsg.Channel, userMsg.Username, "queue_unknown_killer", map[string]string{"USERNAME": userMsg.Username})
+			b.reply(userMsg, msg)
+			return true
+		}
+
+		if err := b.SetQueue(userMsg.Channel, queue); err != nil {
+			msg := b.GetUserString(userMsg.Channel, userMsg.Username, "queue_unknown_killer", map[string]string{"USERNAME": userMsg.Username})
+			b.reply(userMsg, msg)
+			return true
+		}
+	}
+
+	if len(queue) == 0 {
+		msg := b.GetChannelString(userMsg.Channel, "queue_empty", nil)
+		b.SendMessage(userMsg.Channel, msg)
+		return true
+	}
+
+	var names []string
+	for _, name := range queue {
+		names = append(names, b.GetChannelString(userMsg.Channel, "killer_"+name, nil))
+	}
+
+	msg := b.GetChannelString(userMsg.Channel, "queue_list", map[string]string{"QUEUE": strings.Join(names, " → ")})
+	b.SendMessage(userMsg.Channel, msg)
+
+	return true
+}
@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"fmt"
+	"legion-bot-v2/bot/commands"
+	"strings"
+)
+
+func (b *Bot) handleRitualsCommand(call commands.Call) bool {
+	userMsg := call.Message
+	ritualSettings := b.GetState(userMsg.Channel).Settings.Rituals
+
+	if ritualSettings == nil || !ritualSettings.Enabled {
+		return false
+	}
+
+	var rituals []string
+	for _, ritual := range b.Rituals(userMsg.Channel, userMsg.Username) {
+		if ritual.Completed {
+			rituals = append(rituals, fmt.Sprintf("%s ✅", ritual.Name))
+			continue
+		}
+
+		rituals = append(rituals, fmt.Sprintf("%s (%d/%d)", ritual.Name, ritual.Progress, ritual.Count))
+	}
+
+	msg := b.GetUserString(userMsg.Channel, userMsg.Username, "ritual_list", map[string]string{
+		"USERNAME": userMsg.Username,
+		"RITUALS":  strings.Join(rituals, ", "),
+	})
+	b.reply(userMsg, msg)
+
+	return true
+}
@@ -0,0 +1,317 @@
+package rituals
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/samber/do"
+	"hash/fnv"
+	"legion-bot-v2/bot/bloodpoints"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+)
+
+const ritualsPerDay = 3
+
+//go:embed rituals.json
+var definitionsData []byte
+
+type Definition struct {
+	ID      string            `json:"id"`
+	Event   events.Type       `json:"event"`
+	Killer  string            `json:"killer"`
+	Detail  string            `json:"detail"`
+	Count   int               `json:"count"`
+	Survive bool              `json:"survive"`
+	Reward  int               `json:"reward"`
+	Name    map[string]string `json:"name"`
+	Title   map[string]string `json:"title"`
+}
+
+func (d Definition) LocalName(lang string) string {
+	return localize(d.Name, lang, d.ID)
+}
+
+func (d Definition) LocalTitle(lang string) string {
+	return localize(d.Title, lang, "")
+}
+
+func (d Definition) matchesEvent(event events.Event) bool {
+	if d.Event != event.Type {
+		return false
+	}
+	if d.Killer != "" && d.Killer != event.Killer {
+		return false
+	}
+	if d.Detail != "" && d.Detail != event.Detail {
+		return false
+	}
+	return true
+}
+
+func localize(values map[string]string, lang, fallback string) string {
+	if value, ok := values[lang]; ok {
+		return value
+	}
+	if value, ok := values["en"]; ok {
+		return value
+	}
+	return fallback
+}
+
+type Progress struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Progress  int    `json:"progress"`
+	Count     int    `json:"count"`
+	Completed bool   `json:"completed"`
+	Reward    int    `json:"reward"`
+	Title     string `json:"title"`
+}
+
+type Engine interface {
+	Rituals(channel, username string) []Progress
+	Titles(channel, username string) []string
+}
+
+var _ Engine = (*Impl)(nil)
+
+type Impl struct {
+	db.DB
+	chat.Actions
+	i18n.Localiser
+	bloodpoints.Bank
+	definitions []Definition
+}
+
+func New(di *do.Injector) (Engine, error) {
+	var definitions []Definition
+	if err := json.Unmarshal(definitionsData, &definitions); err != nil {
+		return nil, fmt.Errorf("error parsing rituals: %v", err)
+	}
+
+	if len(definitions) < ritualsPerDay {
+		return nil, fmt.Errorf("at least %d rituals are required", ritualsPerDay)
+	}
+
+	for _, d := range definitions {
+		if d.ID == "" || d.Event == "" || d.Count <= 0 || (d.Reward <= 0 && len(d.Title) == 0) {
+			return nil, fmt.Errorf("invalid ritual definition %q", d.ID)
+		}
+	}
+
+	engine := &Impl{
+		DB:          do.MustInvoke[db.DB](di),
+		Actions:     do.MustInvoke[chat.Actions](di),
+		Localiser:   do.MustInvoke[i18n.Localiser](di),
+		Bank:        do.MustInvoke[bloodpoints.Bank](di),
+		definitions: definitions,
+	}
+
+	do.MustInvoke[events.Bus](di).Subscribe(engine.handleEvent)
+
+	return engine, nil
+}
+
+// Day returns the ritual day of the given time, which starts at the channel's reset hour in its timezone.
+func Day(settings db.Settings, now time.Time) string {
+	var resetHour time.Duration
+	if settings.Rituals != nil {
+		resetHour = time.Duration(settings.Rituals.ResetHour) * time.Hour
+	}
+	return now.In(settings.Location()).Add(-resetHour).Format(time.DateOnly)
+}
+
+func (e *Impl) assign(channel, username, day string) []string {
+	hash := fnv.New64a()
+	hash.Write([]byte(channel + "/" + username + "/" + day))
+	r := rand.New(rand.NewSource(int64(hash.Sum64())))
+
+	assigned := make([]string, 0, ritualsPerDay)
+	for _, i := range r.Perm(len(e.definitions))[:ritualsPerDay] {
+		assigned = append(assigned, e.definitions[i].ID)
+	}
+
+	return assigned
+}
+
+func (e *Impl) definition(id string) (Definition, bool) {
+	for _, d := range e.definitions {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+func (e *Impl) Rituals(channel, username string) []Progress {
+	chanState := e.GetState(channel)
+	lang := chanState.Settings.Language
+	day := Day(chanState.Settings, time.Now())
+
+	var state db.RitualState
+	if user, ok := chanState.UserMap[username]; ok && user.Rituals.Day == day {
+		state = user.Rituals
+	}
+
+	result := make([]Progress, 0, ritualsPerDay)
+	for _, id := range e.assign(channel, username, day) {
+		d, ok := e.definition(id)
+		if !ok {
+			continue
+		}
+
+		result = append(result, Progress{
+			ID:        d.ID,
+			Name:      d.LocalName(lang),
+			Progress:  min(state.Progress[d.ID], d.Count),
+			Count:     d.Count,
+			Completed: state.Completed[d.ID],
+			Reward:    d.Reward,
+			Title:     d.LocalTitle(lang),
+		})
+	}
+
+	return result
+}
+
+func (e *Impl) Titles(channel, username string) []string {
+	chanState := e.GetState(channel)
+	lang := chanState.Settings.Language
+
+	result := make([]string, 0)
+
+	user, ok := chanState.UserMap[username]
+	if !ok {
+		return result
+	}
+
+	for _, id := range user.Titles {
+		if d, ok := e.definition(id); ok {
+			result = append(result, d.LocalTitle(lang))
+		}
+	}
+
+	return result
+}
+
+func (e *Impl) handleEvent(event events.Event) {
+	chanState := e.GetState(event.Channel)
+	ritualSettings := chanState.Settings.Rituals
+
+	if ritualSettings == nil || !ritualSettings.Enabled {
+		return
+	}
+
+	if event.Type == events.TypeSessionStart {
+		e.UpdateState(event.Channel, func(chanState *db.ChannelState) {
+			for _, user := range chanState.UserMap {
+				user.Rituals.Pending = nil
+			}
+		})
+		return
+	}
+
+	if event.Username == "" {
+		return
+	}
+
+	day := Day(chanState.Settings, time.Now())
+
+	var completed []Definition
+
+	e.UpdateState(event.Channel, func(chanState *db.ChannelState) {
+		user, ok := chanState.UserMap[event.Username]
+		if !ok {
+			return
+		}
+
+		if user.Rituals.Day != day {
+			user.Rituals = db.RitualState{
+				Day:      day,
+				Assigned: e.assign(event.Channel, event.Username, day),
+			}
+		}
+
+		state := &user.Rituals
+		if state.Progress == nil {
+			state.Progress = make(map[string]int)
+		}
+		if state.Pending == nil {
+			state.Pending = make(map[string]int)
+		}
+		if state.Completed == nil {
+			state.Completed = make(map[string]bool)
+		}
+
+		for _, id := range state.Assigned {
+			d, ok := e.definition(id)
+			if !ok || state.Completed[id] {
+				continue
+			}
+
+			if d.matchesEvent(event) {
+				if d.Survive {
+					state.Pending[id]++
+				} else {
+					state.Progress[id]++
+				}
+			}
+
+			if d.Survive && event.Type == events.TypeSurvive {
+				state.Progress[id] += state.Pending[id]
+				delete(state.Pending, id)
+			}
+
+			if state.Progress[id] < d.Count {
+				continue
+			}
+
+			state.Completed[id] = true
+			if len(d.Title) > 0 && !slices.Contains(user.Titles, d.ID) {
+				user.Titles = append(user.Titles, d.ID)
+			}
+			completed = append(completed, d)
+		}
+	})
+
+	lang := chanState.Settings.Language
+	bpSettings := chanState.Settings.Bloodpoints
+
+	for _, d := range completed {
+		slog.Info("Ritual completed",
+			slog.String("channel", event.Channel),
+			slog.String("username", event.Username),
+			slog.String("id", d.ID),
+		)
+
+		var rewards []string
+
+		if d.Reward > 0 && bpSettings != nil && bpSettings.Enabled {
+			e.Earn(event.Channel, event.Username, d.Reward, "ritual "+d.ID)
+			rewards = append(rewards, e.GetChannelString(chanState.Channel, "ritual_reward_bp", map[string]string{"COUNT": fmt.Sprint(d.Reward)}))
+		}
+		if len(d.Title) > 0 {
+			rewards = append(rewards, e.GetChannelString(chanState.Channel, "ritual_reward_title", map[string]string{"TITLE": d.LocalTitle(lang)}))
+		}
+
+		key := "ritual_completed"
+		if len(rewards) == 0 {
+			key = "ritual_completed_no_reward"
+		}
+
+		msg := e.GetChannelString(chanState.Channel, key, map[string]string{
+			"USERNAME": event.Username,
+			"NAME":     d.LocalName(lang),
+			"REWARD":   strings.Join(rewards, ", "),
+		})
+		e.SendMessage(event.Channel, msg)
+	}
+}
@@ -0,0 +1,110 @@
+package safemode
+
+import (
+	"fmt"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"log/slog"
+	"time"
+)
+
+// maxLogSize is the number of would-be actions kept per channel
+const maxLogSize = 100
+
+var _ chat.Actions = (*Actions)(nil)
+
+// Actions turns the moderation actions into announcements in the channels with safe mode enabled,
+// everything else is passed through, so the game runs unchanged.
+type Actions struct {
+	chat.Actions
+	database  db.DB
+	localiser i18n.Localiser
+}
+
+func New(actions chat.Actions, database db.DB, localiser i18n.Localiser) *Actions {
+	return &Actions{
+		Actions:   actions,
+		database:  database,
+		localiser: localiser,
+	}
+}
+
+func (a *Actions) enabled(channel string) bool {
+	return a.database.GetState(channel).Settings.SafeMode
+}
+
+func (a *Actions) record(channel string, action db.SafeModeAction) {
+	action.Time = time.Now()
+
+	slog.Info("Safe mode action",
+		slog.String("channel", channel),
+		slog.String("type", string(action.Type)),
+		slog.String("username", action.Username),
+		slog.Duration("duration", action.Duration),
+	)
+
+	a.database.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.SafeModeLog = append(chanState.SafeModeLog, action)
+		if len(chanState.SafeModeLog) > maxLogSize {
+			chanState.SafeModeLog = chanState.SafeModeLog[len(chanState.SafeModeLog)-maxLogSize:]
+		}
+	})
+}
+
+func (a *Actions) TimeoutUser(channel, username string, duration time.Duration, reason string) {
+	if !a.enabled(channel) {
+		a.Actions.TimeoutUser(channel, username, duration, reason)
+		return
+	}
+
+	a.record(channel, db.SafeModeAction{Type: db.SafeModeTimeout, Username: username, Duration: duration})
+
+	msg := a.localiser.GetChannelString(channel, "safe_mode_timeout", map[string]string{
+		"USERNAME": username,
+		"COUNT":    fmt.Sprint(int(duration.Seconds())),
+	})
+	a.SendMessage(channel, msg)
+}
+
+func (a *Actions) DeleteMessage(channel, id string) {
+	if !a.enabled(channel) {
+		a.Actions.DeleteMessage(channel, id)
+		return
+	}
+
+	a.record(channel, db.SafeModeAction{Type: db.SafeModeDelete, MessageID: id})
+
+	msg := a.localiser.GetChannelString(channel, "safe_mode_delete", nil)
+	a.SendMessageWithPriority(channel, msg, chat.PriorityLow)
+}
+
+func (a *Actions) SetEmoteMode(channel string, enabled bool) {
+	if !a.enabled(channel) {
+		a.Actions.SetEmoteMode(channel, enabled)
+		return
+	}
+
+	key := "safe_mode_emote_off"
+	actionType := db.SafeModeEmoteOff
+	if enabled {
+		key = "safe_mode_emote_on"
+		actionType = db.SafeModeEmoteOn
+	}
+
+	a.record(channel, db.SafeModeAction{Type: actionType})
+
+	a.SendMessage(channel, a.localiser.GetChannelString(channel, key, nil))
+}
+
+func (a *Actions) UnbanUser(channel, username string) {
+	if !a.enabled(channel) {
+		a.Actions.UnbanUser(channel, username)
+		return
+	}
+
+	a.record(channel, db.SafeModeAction{Type: db.SafeModeUnban, Username: username})
+
+	msg := a.localiser.GetChannelString(channel, "safe_mode_unban", map[string]string{"USERNAME": username})
+	a.SendMessage(channel, msg)
+}
@@ -0,0 +1,301 @@
+package scheduler
+
+import (
+	"errors"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/permissions"
+	"legion-bot-v2/db"
+	"legion-bot-v2/util"
+	"log/slog"
+	"math/rand"
+	"slices"
+	"sort"
+	"time"
+)
+
+var ErrUnknownKiller = errors.New("unknown killer")
+
+type Preview struct {
+	InWindow   bool               `json:"inWindow"`
+	NextWindow time.Time          `json:"nextWindow"`
+	NextKiller string             `json:"nextKiller"`
+	NextETA    time.Time          `json:"nextEta"`
+	Queue      []string           `json:"queue"`
+	Chances    map[string]float64 `json:"chances"`
+	LastKiller string             `json:"lastKiller"`
+}
+
+type Scheduler interface {
+	InWindow(channel string, now time.Time) bool
+	NextWindow(channel string, now time.Time) time.Time
+	NextETA(channel string, streamStart time.Time) time.Time
+	Pick(channel string) killer.Killer
+	Record(channel, name string)
+	SetQueue(channel string, queue []string) error
+	Preview(channel string, streamStart time.Time) Preview
+}
+
+var _ Scheduler = (*Impl)(nil)
+
+type Impl struct {
+	db.DB
+	permissions.Checker
+	killerMap map[string]killer.Killer
+}
+
+func New(di *do.Injector) Scheduler {
+	return &Impl{
+		DB:        do.MustInvoke[db.DB](di),
+		Checker:   do.MustInvoke[permissions.Checker](di),
+		killerMap: do.MustInvoke[map[string]killer.Killer](di),
+	}
+}
+
+// windowsOf returns the killer windows of the channel, missing scheduler settings mean no windows
+func windowsOf(settings db.Settings) []db.ScheduleWindow {
+	if settings.Killers.Scheduler == nil {
+		return nil
+	}
+	return settings.Killers.Scheduler.Windows
+}
+
+func (s *Impl) InWindow(channel string, now time.Time) bool {
+	chanState := s.GetState(channel)
+	windows := windowsOf(chanState.Settings)
+
+	if len(windows) == 0 {
+		return true
+	}
+
+	for _, window := range windows {
+		schedule, err := util.ParseCron(window.Cron, chanState.Settings.Location())
+		if err != nil {
+			continue
+		}
+
+		// the first opening after now-duration is the only one that may still be open
+		if !schedule.Next(now.Add(-window.Duration)).After(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextWindow returns the time the next window opens after now, or a zero time if none are configured.
+func (s *Impl) NextWindow(channel string, now time.Time) time.Time {
+	chanState := s.GetState(channel)
+
+	var next time.Time
+
+	for _, window := range windowsOf(chanState.Settings) {
+		schedule, err := util.ParseCron(window.Cron, chanState.Settings.Location())
+		if err != nil {
+			slog.Warn("Invalid killer window",
+				slog.String("channel", channel),
+				slog.String("cron", window.Cron),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		opening := schedule.Next(now)
+		if next.IsZero() || opening.Before(next) {
+			next = opening
+		}
+	}
+
+	return next
+}
+
+// NextETA estimates when the next killer may appear, or returns a zero time if the stream is offline.
+func (s *Impl) NextETA(channel string, streamStart time.Time) time.Time {
+	chanState := s.GetState(channel)
+	generalKillerSettings := chanState.Settings.Killers.General
+
+	if streamStart.IsZero() {
+		return time.Time{}
+	}
+
+	eta := chanState.Date.Add(generalKillerSettings.DelayBetweenKillers)
+
+	if streamEta := streamStart.Add(generalKillerSettings.DelayAtTheStreamStart); streamEta.After(eta) {
+		eta = streamEta
+	}
+
+	if now := time.Now(); eta.Before(now) {
+		eta = now
+	}
+
+	if !s.InWindow(channel, eta) {
+		eta = s.NextWindow(channel, eta)
+	}
+
+	return eta
+}
+
+func (s *Impl) enabledKillers(channel string) []killer.Killer {
+	var result []killer.Killer
+
+	for _, k := range s.killerMap {
+		if k.Enabled(channel) && s.Check(channel, k) == nil {
+			result = append(result, k)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+
+	return result
+}
+
+func (s *Impl) queued(chanState db.ChannelState) killer.Killer {
+	for _, name := range chanState.Scheduler.Queue {
+		if k, ok := s.killerMap[name]; ok && k.Enabled(chanState.Channel) && s.Check(chanState.Channel, k) == nil {
+			return k
+		}
+	}
+	return nil
+}
+
+// weights applies anti-repeat and pity on top of the configured killer weights.
+func (s *Impl) weights(chanState db.ChannelState, killers []killer.Killer) []float64 {
+	schedulerSettings := chanState.Settings.Killers.Scheduler
+	if schedulerSettings == nil {
+		// no pity and no anti-repeat
+		schedulerSettings = &db.SchedulerSettings{}
+	}
+
+	weights := make([]float64, len(killers))
+
+	for i, k := range killers {
+		weight := float64(k.Weight(chanState.Channel))
+		weight *= 1 + schedulerSettings.PityFactor*float64(chanState.Scheduler.Misses[k.Name()])
+
+		if schedulerSettings.AntiRepeat && len(killers) > 1 && k.Name() == chanState.Scheduler.LastKiller {
+			weight = 0
+		}
+
+		weights[i] = weight
+	}
+
+	return weights
+}
+
+func (s *Impl) Pick(channel string) killer.Killer {
+	chanState := s.GetState(channel)
+
+	if k := s.queued(chanState); k != nil {
+		return k
+	}
+
+	killers := s.enabledKillers(channel)
+	if len(killers) == 0 {
+		return nil
+	}
+
+	weights := s.weights(chanState, killers)
+
+	var totalWeight float64
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+
+	if totalWeight <= 0 {
+		return killers[rand.Intn(len(killers))]
+	}
+
+	r := rand.Float64() * totalWeight
+
+	var runningTotal float64
+	for i, weight := range weights {
+		runningTotal += weight
+		if r < runningTotal {
+			return killers[i]
+		}
+	}
+
+	return killers[len(killers)-1]
+}
+
+func (s *Impl) Record(channel, name string) {
+	s.UpdateState(channel, func(chanState *db.ChannelState) {
+		state := &chanState.Scheduler
+
+		if i := slices.Index(state.Queue, name); i >= 0 {
+			state.Queue = slices.Delete(state.Queue, i, i+1)
+		}
+
+		if state.Misses == nil {
+			state.Misses = make(map[string]int)
+		}
+
+		for otherName := range s.killerMap {
+			if otherName == name {
+				state.Misses[otherName] = 0
+			} else {
+				state.Misses[otherName]++
+			}
+		}
+
+		state.LastKiller = name
+	})
+}
+
+func (s *Impl) SetQueue(channel string, queue []string) error {
+	for _, name := range queue {
+		if _, ok := s.killerMap[name]; !ok {
+			return ErrUnknownKiller
+		}
+	}
+
+	s.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.Scheduler.Queue = slices.Clone(queue)
+	})
+
+	return nil
+}
+
+func (s *Impl) Preview(channel string, streamStart time.Time) Preview {
+	chanState := s.GetState(channel)
+	now := time.Now()
+
+	preview := Preview{
+		InWindow:   s.InWindow(channel, now),
+		NextWindow: s.NextWindow(channel, now),
+		NextETA:    s.NextETA(channel, streamStart),
+		Queue:      chanState.Scheduler.Queue,
+		Chances:    make(map[string]float64),
+		LastKiller: chanState.Scheduler.LastKiller,
+	}
+
+	if preview.Queue == nil {
+		preview.Queue = []string{}
+	}
+
+	if k := s.queued(chanState); k != nil {
+		preview.NextKiller = k.Name()
+		preview.Chances[k.Name()] = 1
+		return preview
+	}
+
+	killers := s.enabledKillers(channel)
+	weights := s.weights(chanState, killers)
+
+	var totalWeight float64
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+
+	for i, k := range killers {
+		if totalWeight <= 0 {
+			preview.Chances[k.Name()] = 1 / float64(len(killers))
+			continue
+		}
+		preview.Chances[k.Name()] = weights[i] / totalWeight
+	}
+
+	return preview
+}
@@ -0,0 +1,204 @@
+package seasons
+
+import (
+	"context"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"github.com/samber/do"
+	"legion-bot-v2/bot/i18n"
+	"legion-bot-v2/db"
+	"legion-bot-v2/twitch/chat"
+	"legion-bot-v2/util"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+const announcedWinners = 3
+
+var scoreWeights = map[string]int{
+	"heals":      1,
+	"unhooks":    2,
+	"stuns":      3,
+	"bodyBlocks": 2,
+	"survives":   1,
+}
+
+type Manager interface {
+	Run(ctx context.Context)
+	Rollover(channel string) (db.Season, error)
+}
+
+var _ Manager = (*Impl)(nil)
+
+type Impl struct {
+	db.DB
+	chat.Actions
+	i18n.Localiser
+}
+
+func New(di *do.Injector) Manager {
+	return &Impl{
+		DB:        do.MustInvoke[db.DB](di),
+		Actions:   do.MustInvoke[chat.Actions](di),
+		Localiser: do.MustInvoke[i18n.Localiser](di),
+	}
+}
+
+func Score(stats map[string]int) int {
+	var score int
+	for stat, weight := range scoreWeights {
+		score += stats[stat] * weight
+	}
+	return score
+}
+
+func (m *Impl) Run(ctx context.Context) {
+	// a panicking check must not take the whole bot down
+	c := cron.New(cron.WithChain(cron.Recover(cron.DefaultLogger)))
+
+	if _, err := c.AddFunc("@every 1m", m.checkAllChannels); err != nil {
+		slog.Error("Failed to schedule season checks",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	c.Start()
+	<-ctx.Done()
+	<-c.Stop().Done()
+}
+
+func (m *Impl) checkAllChannels() {
+	now := time.Now()
+
+	for _, channel := range m.GetAllChannelNames() {
+		chanState := m.GetState(channel)
+		seasonSettings := chanState.Settings.Seasons
+
+		if seasonSettings == nil || !seasonSettings.Enabled {
+			continue
+		}
+
+		if chanState.SeasonStart.IsZero() {
+			m.UpdateState(channel, func(chanState *db.ChannelState) {
+				chanState.SeasonStart = now
+			})
+			continue
+		}
+
+		schedule, err := util.ParseCron(seasonSettings.Schedule, chanState.Settings.Location())
+		if err != nil {
+			slog.Warn("Invalid season schedule",
+				slog.String("channel", channel),
+				slog.String("schedule", seasonSettings.Schedule),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		if now.Before(schedule.Next(chanState.SeasonStart)) {
+			continue
+		}
+
+		if _, err := m.Rollover(channel); err != nil {
+			slog.Error("Failed to roll over season",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+func (m *Impl) Rollover(channel string) (db.Season, error) {
+	now := time.Now()
+
+	var chanState db.ChannelState
+	userStats := make(map[string]map[string]int)
+
+	// the snapshot and the reset happen at once, so no stats recorded in between are lost
+	m.UpdateState(channel, func(state *db.ChannelState) {
+		chanState = *state
+
+		for username, user := range state.UserMap {
+			userStats[username] = user.Stats
+			user.Stats = make(map[string]int)
+		}
+
+		state.Stats = db.NewChannelState(channel).Stats
+		state.SeasonStart = now
+	})
+
+	leaderboardSize := 0
+	if chanState.Settings.Seasons != nil {
+		leaderboardSize = max(0, chanState.Settings.Seasons.LeaderboardSize)
+	}
+
+	season := db.Season{
+		Start:        chanState.SeasonStart,
+		End:          now,
+		ChannelStats: chanState.Stats,
+		Standings:    make([]db.Standing, 0),
+	}
+
+	for username, stats := range userStats {
+		score := Score(stats)
+		if score <= 0 {
+			continue
+		}
+
+		season.Standings = append(season.Standings, db.Standing{
+			Username: username,
+			Score:    score,
+			Stats:    stats,
+		})
+	}
+
+	sort.Slice(season.Standings, func(i, j int) bool {
+		if season.Standings[i].Score == season.Standings[j].Score {
+			return season.Standings[i].Username < season.Standings[j].Username
+		}
+		return season.Standings[i].Score > season.Standings[j].Score
+	})
+
+	for i := range season.Standings {
+		season.Standings[i].Rank = i + 1
+	}
+
+	season.Leaderboard = season.Standings[:min(leaderboardSize, len(season.Standings))]
+
+	season, err := m.AppendSeason(channel, season)
+	if err != nil {
+		return db.Season{}, fmt.Errorf("failed to archive season: %w", err)
+	}
+
+	slog.Info("Season rolled over",
+		slog.String("channel", channel),
+		slog.Uint64("season", season.ID),
+		slog.Int("participants", len(season.Standings)),
+	)
+
+	if chanState.Settings.Disabled || time.Now().Before(chanState.UserTimeout) {
+		return season, nil
+	}
+
+	if len(season.Leaderboard) == 0 {
+		msg := m.GetChannelString(channel, "season_no_winners", map[string]string{"NUMBER": fmt.Sprint(season.ID)})
+		m.SendMessage(channel, msg)
+		return season, nil
+	}
+
+	var winners []string
+	for _, standing := range season.Leaderboard[:min(announcedWinners, len(season.Leaderboard))] {
+		winners = append(winners, fmt.Sprintf("%d. @%s (%d)", standing.Rank, standing.Username, standing.Score))
+	}
+
+	msg := m.GetChannelString(channel, "season_winners", map[string]string{
+		"NUMBER":  fmt.Sprint(season.ID),
+		"WINNERS": strings.Join(winners, ", "),
+	})
+	m.SendMessage(channel, msg)
+
+	return season, nil
+}
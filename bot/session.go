@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/db"
+	"strings"
+	"time"
+)
+
+// prepareSession stores the session before the killer starts, so that the killer already sees its effective settings.
+func (b *Bot) prepareSession(channel, killerName string) {
+	pressure, modifiers := b.difficultyModifiers(channel)
+
+	var addOnIDs []string
+	for _, addOn := range b.RollAddOns(channel, killerName) {
+		addOnIDs = append(addOnIDs, addOn.ID)
+
+		for _, modifier := range addOn.Modifiers {
+			modifier.Source = "addon:" + addOn.ID
+			modifiers = append(modifiers, modifier)
+		}
+	}
+
+	b.UpdateState(channel, func(chanState *db.ChannelState) {
+		chanState.Session = db.SessionState{
+			Killer:       killerName,
+			Start:        time.Now(),
+			Participants: make(map[string]bool),
+			Pressure:     pressure,
+			Modifiers:    modifiers,
+			AddOns:       addOnIDs,
+		}
+	})
+}
+
+func (b *Bot) beginSession(channel, killerName string) {
+	chanState := b.GetState(channel)
+	if chanState.Killer != killerName {
+		b.UpdateState(channel, func(chanState *db.ChannelState) {
+			chanState.Session = db.SessionState{}
+		})
+		return
+	}
+
+	b.Record(channel, killerName)
+	b.announceAddOns(chanState)
+	b.Emit(events.Event{Channel: channel, Type: events.TypeSessionStart, Killer: killerName})
+}
+
+func (b *Bot) announceAddOns(chanState db.ChannelState) {
+	lang := chanState.Settings.Language
+
+	var names []string
+	for _, id := range chanState.Session.AddOns {
+		if addOn, ok := b.AddOn(id); ok {
+			names = append(names, addOn.LocalName(lang))
+		}
+	}
+
+	if len(names) == 0 {
+		return
+	}
+
+	msg := b.GetChannelString(chanState.Channel, "addons_announce", map[string]string{
+		"KILLER": b.GetChannelString(chanState.Channel, "killer_"+chanState.Session.Killer, nil),
+		"ADDONS": strings.Join(names, ", "),
+	})
+	b.SendMessage(chanState.Channel, msg)
+}
+
+func (b *Bot) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.TypeSessionEnd:
+		b.endSession(event.Channel, event.Killer)
+	}
+}
+
+func (b *Bot) endSession(channel, killerName string) {
+	chanState := b.GetState(channel)
+	session := chanState.Session
+
+	if session.Killer != killerName {
+		return
+	}
+
+	var survivors []string
+
+	b.UpdateState(channel, func(chanState *db.ChannelState) {
+		for username := range session.Participants {
+			user, ok := chanState.UserMap[username]
+			if !ok || user.Health == "hooked" || user.Health == "dead" {
+				continue
+			}
+
+			user.Stats["survives"]++
+			survivors = append(survivors, username)
+		}
+
+		chanState.LastSession = session
+		chanState.Session = db.SessionState{}
+	})
+
+	for _, username := range survivors {
+		b.Emit(events.Event{Channel: channel, Username: username, Type: events.TypeSurvive, Killer: killerName})
+	}
+}
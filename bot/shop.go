@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"legion-bot-v2/bot/bloodpoints"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/db"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	ShopItemMedkit   = "medkit"
+	ShopItemOffering = "offering"
+	ShopItemSummon   = "summon"
+)
+
+func (b *Bot) handleBalanceCommand(call commands.Call) bool {
+	userMsg := call.Message
+	bpSettings := b.GetState(userMsg.Channel).Settings.Bloodpoints
+
+	if bpSettings == nil || !bpSettings.Enabled {
+		return false
+	}
+
+	msg := b.GetUserString(userMsg.Channel, userMsg.Username, "bp_balance", map[string]string{
+		"USERNAME": userMsg.Username,
+		"COUNT":    fmt.Sprint(b.Balance(userMsg.Channel, userMsg.Username)),
+	})
+	b.reply(userMsg, msg)
+
+	return true
+}
+
+func (b *Bot) handleShopCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
+	bpSettings := chanState.Settings.Bloodpoints
+
+	if bpSettings == nil || !bpSettings.Enabled {
+		return false
+	}
+
+	var items []string
+
+	if bpSettings.MedkitPrice > 0 {
+		items = append(items, fmt.Sprintf("%s (%d)", ShopItemMedkit, bpSettings.MedkitPrice))
+	}
+	if bpSettings.OfferingPrice > 0 {
+		items = append(items, fmt.Sprintf("%s (%d)", ShopItemOffering, bpSettings.OfferingPrice))
+	}
+	if bpSettings.SummonPrice > 0 {
+		items = append(items, fmt.Sprintf("%s <killer> (%d)", ShopItemSummon, bpSettings.SummonPrice))
+	}
+
+	if len(items) == 0 {
+		msg := b.GetChannelString(userMsg.Channel, "shop_empty", nil)
+		b.SendMessage(userMsg.Channel, msg)
+		return true
+	}
+
+	msg := b.GetChannelString(userMsg.Channel, "shop_list", map[string]string{"ITEMS": strings.Join(items, ", ")})
+	b.SendMessage(userMsg.Channel, msg)
+
+	return true
+}
+
+func (b *Bot) handleBuyCommand(call commands.Call) bool {
+	userMsg := call.Message
+	chanState := b.GetState(userMsg.Channel)
+	bpSettings := chanState.Settings.Bloodpoints
+	user := chanState.UserMap[userMsg.Username]
+
+	if bpSettings == nil || !bpSettings.Enabled {
+		return false
+	}
+
+	args := strings.Fields(strings.ToLower(call.Rest()))
+	if len(args) == 0 {
+		return b.handleShopCommand(call)
+	}
+
+	switch args[0] {
+	case ShopItemMedkit:
+		if bpSettings.MedkitPrice <= 0 {
+			break
+		}
+
+		if user.Health == "hooked" {
+			msg := b.GetUserString(userMsg.Channel, userMsg.Username, "cant_do_rn", map[string]string{"USERNAME": userMsg.Username})
+			b.reply(userMsg, msg)
+			return true
+		}
+
+		if user.Health == "healthy" {
+			msg := b.GetUserString(userMsg.Channel, userMsg.Username, "shop_medkit_useless", map[string]string{"USERNAME": userMsg.Username})
+			b.reply(userMsg, msg)
+			return true
+		}
+
+		if _, ok := b.spendBloodpoints(userMsg, bpSettings.MedkitPrice, ShopItemMedkit); !ok {
+			return true
+		}
+
+		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+			chanState.UserMap[userMsg.Username].Health = "healthy"
+		})
+
+		b.StopTimer(userMsg.Channel, userMsg.Username)
+
+		msg := b.GetChannelString(userMsg.Channel, "shop_medkit", map[string]string{"USERNAME": userMsg.Username})
+		b.SendMessage(userMsg.Channel, msg)
+
+		return true
+
+	case ShopItemOffering:
+		if bpSettings.OfferingPrice <= 0 {
+			break
+		}
+
+		if _, ok := b.spendBloodpoints(userMsg, bpSettings.OfferingPrice, ShopItemOffering); !ok {
+			return true
+		}
+
+		immuneUntil := time.Now().Add(bpSettings.OfferingDuration)
+		if user.IsImmune() {
+			immuneUntil = user.ImmuneUntil.Add(bpSettings.OfferingDuration)
+		}
+
+		b.UpdateState(userMsg.Channel, func(chanState *db.ChannelState) {
+			chanState.UserMap[userMsg.Username].ImmuneUntil = immuneUntil
+		})
+
+		msg := b.GetChannelString(userMsg.Channel, "shop_offering", map[string]string{
+			"USERNAME": userMsg.Username,
+			"COUNT":    fmt.Sprint(int(bpSettings.OfferingDuration.Minutes())),
+		})
+		b.SendMessage(userMsg.Channel, msg)
+
+		return true
+
+	case ShopItemSummon:
+		if bpSettings.SummonPrice <= 0 {
+			break
+		}
+
+		if len(args) < 2 {
+			msg := b.GetUserString(userMsg.Channel, userMsg.Username, "shop_unknown_item", map[string]string{"USERNAME": userMsg.Username})
+			b.reply(userMsg, msg)
+			return true
+		}
+
+		name := args[1]
+
+		k, ok := b.killerMap[name]
+		if !ok || !k.Enabled(userMsg.Channel) || chanState.Killer != "" || b.Check(userMsg.Channel, k) != nil {
+			msg := b.GetUserString(userMsg.Channel, userMsg.Username, "shop_summon_failed", map[string]string{"USERNAME": userMsg.Username})
+			b.reply(userMsg, msg)
+			return true
+		}
+
+		purchase, ok := b.spendBloodpoints(userMsg, bpSettings.SummonPrice, ShopItemSummon+" "+name)
+		if !ok {
+			return true
+		}
+
+		if err := b.StartSpecificKiller(userMsg.Channel, name); err != nil {
+			slog.Error("Failed to summon killer from the shop",
+				slog.String("channel", userMsg.Channel),
+				slog.String("name", name),
+				slog.Any("error", err),
+			)
+
+			if _, err := b.Refund(userMsg.Channel, purchase.ID); err != nil {
+				slog.Error("Failed to refund the summon",
+					slog.String("channel", userMsg.Channel),
+					slog.String("username", userMsg.Username),
+					slog.Any("error", err),
+				)
+			}
+
+			msg := b.GetUserString(userMsg.Channel, userMsg.Username, "shop_summon_failed", map[string]string{"USERNAME": userMsg.Username})
+			b.reply(userMsg, msg)
+			return true
+		}
+
+		msg := b.GetChannelString(userMsg.Channel, "shop_summon", map[string]string{
+			"USERNAME": userMsg.Username,
+			"KILLER":   b.GetChannelString(userMsg.Channel, "killer_"+name, nil),
+		})
+		b.SendMessage(userMsg.Channel, msg)
+
+		return true
+	}
+
+	msg := b.GetUserString(userMsg.Channel, userMsg.Username, "shop_unknown_item", map[string]string{"USERNAME": userMsg.Username})
+	b.reply(userMsg, msg)
+
+	return true
+}
+
+func (b *Bot) spendBloodpoints(userMsg db.Message, price int, reason string) (db.LedgerEntry, bool) {
+	purchase, err := b.Spend(userMsg.Channel, userMsg.Username, price, reason)
+	if errors.Is(err, bloodpoints.ErrNotEnoughBloodpoints) {
+		msg := b.GetUserString(userMsg.Channel, userMsg.Username, "shop_not_enough", map[string]string{
+			"USERNAME": userMsg.Username,
+			"COUNT":    fmt.Sprint(price),
+		})
+		b.reply(userMsg, msg)
+		return db.LedgerEntry{}, false
+	}
+	if err != nil {
+		slog.Error("Failed to spend bloodpoints",
+			slog.String("channel", userMsg.Channel),
+			slog.String("username", userMsg.Username),
+			slog.Any("error", err),
+		)
+		return db.LedgerEntry{}, false
+	}
+
+	return purchase, true
+}
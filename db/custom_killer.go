@@ -0,0 +1,55 @@
+package db
+
+import "time"
+
+type CustomKillerTriggerType string
+
+var (
+	CustomKillerTriggerCommand = CustomKillerTriggerType("command")
+	CustomKillerTriggerRegex   = CustomKillerTriggerType("regex")
+	CustomKillerTriggerRandom  = CustomKillerTriggerType("random")
+)
+
+type CustomKillerEffectType string
+
+var (
+	CustomKillerEffectInjure  = CustomKillerEffectType("injure")
+	CustomKillerEffectHook    = CustomKillerEffectType("hook")
+	CustomKillerEffectDelete  = CustomKillerEffectType("delete")
+	CustomKillerEffectSend    = CustomKillerEffectType("send")
+	CustomKillerEffectTimeout = CustomKillerEffectType("timeout")
+	CustomKillerEffectEnd     = CustomKillerEffectType("end")
+)
+
+type CustomKillerEffect struct {
+	Type     CustomKillerEffectType `json:"type" yaml:"type"`
+	Text     string                 `json:"text,omitempty" yaml:"text"`
+	Duration time.Duration          `json:"duration,omitempty" yaml:"duration"`
+}
+
+type CustomKillerTrigger struct {
+	Type    CustomKillerTriggerType `json:"type" yaml:"type"`
+	Command string                  `json:"command,omitempty" yaml:"command"`
+	Pattern string                  `json:"pattern,omitempty" yaml:"pattern"`
+	Chance  float64                 `json:"chance" yaml:"chance"`
+	Effects []CustomKillerEffect    `json:"effects" yaml:"effects"`
+}
+
+type CustomKillerEndConditions struct {
+	Timeout  time.Duration `json:"timeout" yaml:"timeout"`
+	MaxHooks int           `json:"maxHooks" yaml:"maxHooks"`
+	MaxHits  int           `json:"maxHits" yaml:"maxHits"`
+}
+
+type CustomKiller struct {
+	Name                    string                    `json:"name" yaml:"name"`
+	DisplayName             string                    `json:"displayName" yaml:"displayName"`
+	Enabled                 bool                      `json:"enabled" yaml:"enabled"`
+	Weight                  int                       `json:"weight" yaml:"weight"`
+	StartMessage            string                    `json:"startMessage" yaml:"startMessage"`
+	EndMessage              string                    `json:"endMessage" yaml:"endMessage"`
+	HookBanTime             time.Duration             `json:"hookBanTime" yaml:"hookBanTime"`
+	MinDelayBetweenTriggers time.Duration             `json:"minDelayBetweenTriggers" yaml:"minDelayBetweenTriggers"`
+	Triggers                []CustomKillerTrigger     `json:"triggers" yaml:"triggers"`
+	End                     CustomKillerEndConditions `json:"end" yaml:"end"`
+}
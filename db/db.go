@@ -6,5 +6,12 @@ type DB interface {
 	GetAllStates() []ChannelState
 	GetAllChannelNames() []string
 	ReadAllStates(callback func(state *ChannelState))
+	AppendLedgerEntry(channel string, entry LedgerEntry) (LedgerEntry, error)
+	GetLedgerEntries(channel string) []LedgerEntry
+	RefundLedgerEntry(channel string, id uint64) (LedgerEntry, error)
+	AppendPunishment(channel string, punishment Punishment) (Punishment, error)
+	GetPunishments(channel string) []Punishment
+	AppendSeason(channel string, season Season) (Season, error)
+	GetSeasons(channel string) []Season
 	Close()
 }
@@ -20,8 +20,12 @@ func NewDatabase(filePath string) (DB, error) {
 	}
 
 	err = db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte("channels"))
-		return err
+		for _, name := range []string{"channels", "ledger", "seasons", "punishments"} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create buckets: %w", err)
@@ -36,37 +40,42 @@ func (db *Impl) Close() {
 
 func (db *Impl) UpdateState(channel string, callback func(state *ChannelState)) {
 	err := db.db.Update(func(tx *bbolt.Tx) error {
-		bucket := tx.Bucket([]byte("channels"))
-		if bucket == nil {
-			return errors.New("bucket not found")
-		}
+		return updateState(tx, channel, callback)
+	})
 
-		var state ChannelState
-		data := bucket.Get([]byte(channel))
-		if data != nil {
-			if err := json.Unmarshal(data, &state); err != nil {
-				return err
-			}
-		} else {
-			state = NewChannelState(channel)
-		}
+	if err != nil {
+		slog.Error("Failed to update state for channel",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+	}
+}
 
-		callback(&state)
+// updateState runs the callback on the channel state within the transaction
+func updateState(tx *bbolt.Tx, channel string, callback func(state *ChannelState)) error {
+	bucket := tx.Bucket([]byte("channels"))
+	if bucket == nil {
+		return errors.New("bucket not found")
+	}
 
-		data, err := json.Marshal(state)
-		if err != nil {
+	var state ChannelState
+	data := bucket.Get([]byte(channel))
+	if data != nil {
+		if err := json.Unmarshal(data, &state); err != nil {
 			return err
 		}
+	} else {
+		state = NewChannelState(channel)
+	}
 
-		return bucket.Put([]byte(channel), data)
-	})
+	callback(&state)
 
+	data, err := json.Marshal(state)
 	if err != nil {
-		slog.Error("Failed to update state for channel",
-			slog.String("channel", channel),
-			slog.Any("error", err),
-		)
+		return err
 	}
+
+	return bucket.Put([]byte(channel), data)
 }
 
 func (db *Impl) GetState(channel string) ChannelState {
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+type LedgerEntryKind string
+
+var (
+	LedgerEntryEarn     = LedgerEntryKind("earn")
+	LedgerEntryPurchase = LedgerEntryKind("purchase")
+	LedgerEntryRefund   = LedgerEntryKind("refund")
+)
+
+var (
+	ErrLedgerEntryNotFound = errors.New("ledger entry not found")
+	ErrAlreadyRefunded     = errors.New("ledger entry is already refunded")
+	ErrNotRefundable       = errors.New("only purchases can be refunded")
+)
+
+type LedgerEntry struct {
+	ID       uint64          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Username string          `json:"username"`
+	Kind     LedgerEntryKind `json:"kind"`
+	Reason   string          `json:"reason"`
+	Amount   int             `json:"amount"`
+	RefundOf uint64          `json:"refundOf,omitempty"`
+}
+
+func (db *Impl) AppendLedgerEntry(channel string, entry LedgerEntry) (LedgerEntry, error) {
+	err := db.db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		entry, err = appendLedgerEntry(tx, channel, entry)
+		return err
+	})
+	if err != nil {
+		slog.Error("Failed to append ledger entry",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return LedgerEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// RefundLedgerEntry gives the bloodpoints of a purchase back and records the refund in a single transaction,
+// so a purchase is refunded at most once and the balance never drifts from the ledger
+func (db *Impl) RefundLedgerEntry(channel string, id uint64) (LedgerEntry, error) {
+	var refund LedgerEntry
+
+	err := db.db.Update(func(tx *bbolt.Tx) error {
+		channelBucket, err := ledgerBucket(tx, channel)
+		if err != nil {
+			return err
+		}
+
+		var original *LedgerEntry
+
+		err = channelBucket.ForEach(func(k, v []byte) error {
+			var entry LedgerEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			if entry.RefundOf == id {
+				return ErrAlreadyRefunded
+			}
+			if entry.ID == id {
+				original = &entry
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if original == nil {
+			return ErrLedgerEntryNotFound
+		}
+		if original.Kind != LedgerEntryPurchase {
+			return ErrNotRefundable
+		}
+
+		err = updateState(tx, channel, func(state *ChannelState) {
+			if _, ok := state.UserMap[original.Username]; !ok {
+				state.UserMap[original.Username] = NewUser()
+			}
+
+			state.UserMap[original.Username].Bloodpoints -= original.Amount
+		})
+		if err != nil {
+			return err
+		}
+
+		refund, err = appendLedgerEntry(tx, channel, LedgerEntry{
+			Username: original.Username,
+			Kind:     LedgerEntryRefund,
+			Reason:   original.Reason,
+			Amount:   -original.Amount,
+			RefundOf: original.ID,
+		})
+		return err
+	})
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+
+	return refund, nil
+}
+
+func ledgerBucket(tx *bbolt.Tx, channel string) (*bbolt.Bucket, error) {
+	bucket := tx.Bucket([]byte("ledger"))
+	if bucket == nil {
+		return nil, errors.New("bucket not found")
+	}
+
+	return bucket.CreateBucketIfNotExists([]byte(channel))
+}
+
+func appendLedgerEntry(tx *bbolt.Tx, channel string, entry LedgerEntry) (LedgerEntry, error) {
+	channelBucket, err := ledgerBucket(tx, channel)
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+
+	id, err := channelBucket.NextSequence()
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+
+	entry.ID = id
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+
+	if err := channelBucket.Put(sequenceKey(id), data); err != nil {
+		return LedgerEntry{}, err
+	}
+
+	return entry, nil
+}
+
+func (db *Impl) GetLedgerEntries(channel string) []LedgerEntry {
+	entries := make([]LedgerEntry, 0)
+
+	err := db.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("ledger"))
+		if bucket == nil {
+			return errors.New("bucket not found")
+		}
+
+		channelBucket := bucket.Bucket([]byte(channel))
+		if channelBucket == nil {
+			return nil
+		}
+
+		return channelBucket.ForEach(func(k, v []byte) error {
+			var entry LedgerEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+
+	if err != nil {
+		slog.Error("Failed to get ledger entries",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return []LedgerEntry{}
+	}
+
+	return entries
+}
+
+func sequenceKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
@@ -3,9 +3,38 @@ package db
 import "time"
 
 type User struct {
-	Health string         `json:"health"`
-	Marked bool           `json:"marked"`
-	Stats  map[string]int `json:"stats"`
+	Health      string         `json:"health"`
+	Marked      bool           `json:"marked"`
+	Stats       map[string]int `json:"stats"`
+	Bloodpoints int            `json:"bloodpoints"`
+	ImmuneUntil time.Time      `json:"immuneUntil"`
+
+	Achievements        map[string]time.Time `json:"achievements"`
+	AchievementProgress map[string]int       `json:"achievementProgress"`
+
+	Rituals RitualState `json:"rituals"`
+	Titles  []string    `json:"titles"`
+
+	// Language overrides the channel language for the replies aimed at this user
+	Language string `json:"language,omitempty"`
+
+	// Roles are the chat roles of the user as of their last message
+	Roles UserRoles `json:"roles"`
+
+	LastSeen time.Time `json:"lastSeen"`
+	// LastStream is the start time of the last stream the user chatted in, Streams counts such streams
+	LastStream time.Time `json:"lastStream"`
+	Streams    int       `json:"streams"`
+}
+
+type UserRoles struct {
+	IsMod   bool `json:"isMod"`
+	IsVIP   bool `json:"isVip"`
+	SubTier int  `json:"subTier"`
+}
+
+func (u *User) IsImmune() bool {
+	return time.Now().Before(u.ImmuneUntil)
 }
 
 type ChannelState struct {
@@ -19,6 +48,73 @@ type ChannelState struct {
 	UserTimeout time.Time        `json:"userTimeout"`
 	Subs        ChannelSubs      `json:"subs"`
 	Steam       SteamState       `json:"steam"`
+	Session     SessionState     `json:"session"`
+	LastSession SessionState     `json:"lastSession"`
+	SeasonStart time.Time        `json:"seasonStart"`
+	Scheduler   SchedulerState   `json:"scheduler"`
+	SafeModeLog []SafeModeAction `json:"safeModeLog"`
+	Budget      BudgetState      `json:"budget"`
+}
+
+// BudgetState is the moderation budget spent during the current stream
+type BudgetState struct {
+	Spent time.Duration          `json:"spent"`
+	Users map[string]*UserBudget `json:"users"`
+	// Skipped counts the timeouts that were only announced because the budget ran out
+	Skipped int `json:"skipped"`
+
+	SessionStart time.Time `json:"sessionStart"`
+	SessionUsers []string  `json:"sessionUsers"`
+}
+
+type UserBudget struct {
+	Spent    time.Duration `json:"spent"`
+	Timeouts int           `json:"timeouts"`
+}
+
+type SafeModeActionType string
+
+var (
+	SafeModeTimeout  = SafeModeActionType("timeout")
+	SafeModeDelete   = SafeModeActionType("delete")
+	SafeModeUnban    = SafeModeActionType("unban")
+	SafeModeEmoteOn  = SafeModeActionType("emote_on")
+	SafeModeEmoteOff = SafeModeActionType("emote_off")
+)
+
+// SafeModeAction is a moderation action that was skipped because of the safe mode
+type SafeModeAction struct {
+	Time      time.Time          `json:"time"`
+	Type      SafeModeActionType `json:"type"`
+	Username  string             `json:"username,omitempty"`
+	Duration  time.Duration      `json:"duration,omitempty"`
+	MessageID string             `json:"messageId,omitempty"`
+}
+
+type SchedulerState struct {
+	Queue      []string       `json:"queue"`
+	Misses     map[string]int `json:"misses"`
+	LastKiller string         `json:"lastKiller"`
+}
+
+type SessionState struct {
+	Killer       string          `json:"killer"`
+	Start        time.Time       `json:"start"`
+	Participants map[string]bool `json:"participants"`
+
+	Pressure  float64           `json:"pressure"`
+	Modifiers []SettingModifier `json:"modifiers"`
+	AddOns    []string          `json:"addOns"`
+
+	Clips []string `json:"clips"`
+}
+
+type RitualState struct {
+	Day       string          `json:"day"`
+	Assigned  []string        `json:"assigned"`
+	Progress  map[string]int  `json:"progress"`
+	Pending   map[string]int  `json:"pending"`
+	Completed map[string]bool `json:"completed"`
 }
 
 type SteamState struct {
@@ -27,17 +123,22 @@ type SteamState struct {
 }
 
 type ChannelSubs struct {
-	RaidID      string `json:"raidId"`
-	StreamStart string `json:"streamStart"`
-	StreamEnd   string `json:"streamEnd"`
+	RaidID        string `json:"raidId"`
+	StreamStart   string `json:"streamStart"`
+	StreamEnd     string `json:"streamEnd"`
+	ChannelUpdate string `json:"channelUpdate"`
 }
 
 type Message struct {
-	ID       string
-	Channel  string
-	Username string
-	IsMod    bool
-	Text     string
+	ID           string
+	Channel      string
+	Username     string
+	IsMod        bool
+	IsVIP        bool
+	IsSubscriber bool
+	// SubTier is the subscription tier from 1 to 3, or 0 for non-subscribers
+	SubTier int
+	Text    string
 }
 
 type PartialMessage struct {
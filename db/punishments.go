@@ -0,0 +1,112 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+type PunishmentKind string
+
+var (
+	PunishmentTimeout  = PunishmentKind("timeout")
+	PunishmentUnban    = PunishmentKind("unban")
+	PunishmentDelete   = PunishmentKind("delete")
+	PunishmentEmoteOn  = PunishmentKind("emote_on")
+	PunishmentEmoteOff = PunishmentKind("emote_off")
+)
+
+// Punishment is a moderation action issued by the bot, reverting one appends the opposite action with RevertOf set
+type Punishment struct {
+	ID        uint64         `json:"id"`
+	Time      time.Time      `json:"time"`
+	Kind      PunishmentKind `json:"kind"`
+	Username  string         `json:"username,omitempty"`
+	MessageID string         `json:"messageId,omitempty"`
+	Duration  time.Duration  `json:"duration,omitempty"`
+	Reason    string         `json:"reason,omitempty"`
+	RevertOf  uint64         `json:"revertOf,omitempty"`
+}
+
+// Expires returns when a timeout ends by itself
+func (p Punishment) Expires() time.Time {
+	return p.Time.Add(p.Duration)
+}
+
+func (db *Impl) AppendPunishment(channel string, punishment Punishment) (Punishment, error) {
+	err := db.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("punishments"))
+		if bucket == nil {
+			return errors.New("bucket not found")
+		}
+
+		channelBucket, err := bucket.CreateBucketIfNotExists([]byte(channel))
+		if err != nil {
+			return err
+		}
+
+		id, err := channelBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		punishment.ID = id
+		if punishment.Time.IsZero() {
+			punishment.Time = time.Now()
+		}
+
+		data, err := json.Marshal(punishment)
+		if err != nil {
+			return err
+		}
+
+		return channelBucket.Put(sequenceKey(id), data)
+	})
+	if err != nil {
+		slog.Error("Failed to append punishment",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return Punishment{}, err
+	}
+
+	return punishment, nil
+}
+
+func (db *Impl) GetPunishments(channel string) []Punishment {
+	punishments := make([]Punishment, 0)
+
+	err := db.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("punishments"))
+		if bucket == nil {
+			return errors.New("bucket not found")
+		}
+
+		channelBucket := bucket.Bucket([]byte(channel))
+		if channelBucket == nil {
+			return nil
+		}
+
+		return channelBucket.ForEach(func(k, v []byte) error {
+			var punishment Punishment
+			if err := json.Unmarshal(v, &punishment); err != nil {
+				return err
+			}
+			punishments = append(punishments, punishment)
+			return nil
+		})
+	})
+
+	if err != nil {
+		slog.Error("Failed to get punishments",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return []Punishment{}
+	}
+
+	return punishments
+}
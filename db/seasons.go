@@ -0,0 +1,98 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+type Standing struct {
+	Rank     int            `json:"rank"`
+	Username string         `json:"username"`
+	Score    int            `json:"score"`
+	Stats    map[string]int `json:"stats"`
+}
+
+type Season struct {
+	ID           uint64         `json:"id"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	ChannelStats map[string]int `json:"channelStats"`
+	Leaderboard  []Standing     `json:"leaderboard"`
+	Standings    []Standing     `json:"standings"`
+}
+
+func (db *Impl) AppendSeason(channel string, season Season) (Season, error) {
+	err := db.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("seasons"))
+		if bucket == nil {
+			return errors.New("bucket not found")
+		}
+
+		channelBucket, err := bucket.CreateBucketIfNotExists([]byte(channel))
+		if err != nil {
+			return err
+		}
+
+		id, err := channelBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		season.ID = id
+
+		data, err := json.Marshal(season)
+		if err != nil {
+			return err
+		}
+
+		return channelBucket.Put(sequenceKey(id), data)
+	})
+	if err != nil {
+		slog.Error("Failed to append season",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return Season{}, err
+	}
+
+	return season, nil
+}
+
+func (db *Impl) GetSeasons(channel string) []Season {
+	seasons := make([]Season, 0)
+
+	err := db.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte("seasons"))
+		if bucket == nil {
+			return errors.New("bucket not found")
+		}
+
+		channelBucket := bucket.Bucket([]byte(channel))
+		if channelBucket == nil {
+			return nil
+		}
+
+		return channelBucket.ForEach(func(k, v []byte) error {
+			var season Season
+			if err := json.Unmarshal(v, &season); err != nil {
+				return err
+			}
+			seasons = append(seasons, season)
+			return nil
+		})
+	})
+
+	if err != nil {
+		slog.Error("Failed to get seasons",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return []Season{}
+	}
+
+	return seasons
+}
@@ -6,11 +6,39 @@ import (
 )
 
 type Settings struct {
-	Disabled bool            `json:"disabled"`
-	Language string          `json:"language"`
-	Killers  KillersSettings `json:"killers"`
-	Chat     ChatSettings    `json:"chat"`
-	Steam    SteamSettings   `json:"steam"`
+	Disabled    bool                 `json:"disabled"`
+	SafeMode    bool                 `json:"safeMode"`
+	Language    string               `json:"language"`
+	Timezone    string               `json:"timezone"`
+	Killers     KillersSettings      `json:"killers"`
+	Chat        ChatSettings         `json:"chat"`
+	Steam       SteamSettings        `json:"steam"`
+	Bloodpoints *BloodpointsSettings `json:"bloodpoints"`
+	Seasons     *SeasonsSettings     `json:"seasons"`
+	Rituals     *RitualsSettings     `json:"rituals"`
+	Difficulty  *DifficultySettings  `json:"difficulty"`
+	AddOns      *AddOnsSettings      `json:"addOns"`
+	Commands    *CommandsSettings    `json:"commands"`
+	Greetings   *GreetingsSettings   `json:"greetings"`
+	Clips       *ClipsSettings       `json:"clips"`
+	Protection  *ProtectionSettings  `json:"protection"`
+	Budget      *BudgetSettings      `json:"budget"`
+
+	CustomKillers   []CustomKiller    `json:"customKillers"`
+	LocaleOverrides map[string]string `json:"localeOverrides"`
+}
+
+func (s Settings) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
 }
 
 type SteamSettings struct {
@@ -23,10 +51,21 @@ type ChatSettings struct {
 	StartKillerOnRaid  bool   `json:"startKillerOnRaid"`
 	FollowRaids        bool   `json:"followRaids"`
 	FollowRaidsMessage string `json:"followRaidsMessage"`
+	// PlainReplies sends command responses as regular messages instead of threaded replies
+	PlainReplies bool `json:"plainReplies"`
+}
+
+// ReplyParent returns the message ID a command response should reply to, or an empty string for a plain message.
+func (s Settings) ReplyParent(userMsg Message) string {
+	if s.Chat.PlainReplies {
+		return ""
+	}
+	return userMsg.ID
 }
 
 type KillersSettings struct {
 	General   *GeneralKillerSettings `json:"general"`
+	Scheduler *SchedulerSettings     `json:"scheduler"`
 	Legion    *LegionSettings        `json:"legion"`
 	GhostFace *GhostFaceSettings     `json:"ghostface"`
 	Doctor    *DoctorSettings        `json:"doctor"`
@@ -37,9 +76,12 @@ type KillersSettings struct {
 func DefaultSettings() Settings {
 	return Settings{
 		Disabled: os.Getenv("ENVIRONMENT") == "production",
+		SafeMode: false,
 		Language: "ru",
+		Timezone: "UTC",
 		Killers: KillersSettings{
 			General:   DefaultGeneralKillerSettings(),
+			Scheduler: DefaultSchedulerSettings(),
 			Legion:    DefaultLegionSettings(),
 			GhostFace: DefaultGhostFaceSettings(),
 			Doctor:    DefaultDoctorSettings(),
@@ -49,13 +91,240 @@ func DefaultSettings() Settings {
 		Chat: ChatSettings{
 			FollowRaidsMessage: "+250",
 		},
+		Bloodpoints: DefaultBloodpointsSettings(),
+		Seasons:     DefaultSeasonsSettings(),
+		Rituals:     DefaultRitualsSettings(),
+		Difficulty:  DefaultDifficultySettings(),
+		AddOns:      DefaultAddOnsSettings(),
+		Commands:    DefaultCommandsSettings(),
+		Greetings:   DefaultGreetingsSettings(),
+		Clips:       DefaultClipsSettings(),
+		Protection:  DefaultProtectionSettings(),
+		Budget:      DefaultBudgetSettings(),
+
+		CustomKillers:   []CustomKiller{},
+		LocaleOverrides: make(map[string]string),
+	}
+}
+
+type BloodpointsSettings struct {
+	Enabled          bool          `json:"enabled"`
+	HealReward       int           `json:"healReward"`
+	UnhookReward     int           `json:"unhookReward"`
+	StunReward       int           `json:"stunReward"`
+	RevealReward     int           `json:"revealReward"`
+	SurviveReward    int           `json:"surviveReward"`
+	MedkitPrice      int           `json:"medkitPrice"`
+	OfferingPrice    int           `json:"offeringPrice"`
+	OfferingDuration time.Duration `json:"offeringDuration"`
+	SummonPrice      int           `json:"summonPrice"`
+}
+
+func DefaultBloodpointsSettings() *BloodpointsSettings {
+	return &BloodpointsSettings{
+		Enabled:          true,
+		HealReward:       300,
+		UnhookReward:     500,
+		StunReward:       1000,
+		RevealReward:     1000,
+		SurviveReward:    250,
+		MedkitPrice:      2000,
+		OfferingPrice:    5000,
+		OfferingDuration: 30 * time.Minute,
+		SummonPrice:      10000,
+	}
+}
+
+type SeasonsSettings struct {
+	Enabled         bool   `json:"enabled"`
+	Schedule        string `json:"schedule"`
+	LeaderboardSize int    `json:"leaderboardSize"`
+}
+
+func DefaultSeasonsSettings() *SeasonsSettings {
+	return &SeasonsSettings{
+		Enabled:         false,
+		Schedule:        "0 0 1 * *",
+		LeaderboardSize: 10,
+	}
+}
+
+type RitualsSettings struct {
+	Enabled   bool `json:"enabled"`
+	ResetHour int  `json:"resetHour"`
+}
+
+func DefaultRitualsSettings() *RitualsSettings {
+	return &RitualsSettings{
+		Enabled:   true,
+		ResetHour: 6,
+	}
+}
+
+type GreetingsSettings struct {
+	Enabled     bool `json:"enabled"`
+	NewChatters bool `json:"newChatters"`
+	// ReturningAfter is how long a viewer must be away to be welcomed back, zero disables it
+	ReturningAfter time.Duration `json:"returningAfter"`
+	// Milestones are the numbers of attended streams that are celebrated
+	Milestones []int         `json:"milestones"`
+	Cooldown   time.Duration `json:"cooldown"`
+}
+
+func DefaultGreetingsSettings() *GreetingsSettings {
+	return &GreetingsSettings{
+		Enabled:        false,
+		NewChatters:    true,
+		ReturningAfter: 30 * 24 * time.Hour,
+		Milestones:     []int{10, 50, 100},
+		Cooldown:       30 * time.Second,
+	}
+}
+
+type ClipsSettings struct {
+	// Markers adds a stream marker at the start and the end of sessions and at their notable moments
+	Markers bool `json:"markers"`
+	// AutoClips clips hooks, stuns and reveals
+	AutoClips        bool          `json:"autoClips"`
+	AutoClipCooldown time.Duration `json:"autoClipCooldown"`
+}
+
+func DefaultClipsSettings() *ClipsSettings {
+	return &ClipsSettings{
+		Markers:          false,
+		AutoClips:        false,
+		AutoClipCooldown: 5 * time.Minute,
+	}
+}
+
+// ProtectionSettings lists the users that are never timed out, they still play but their punishments are fake.
+// The streamer, the bot owner and the bots are always protected.
+type ProtectionSettings struct {
+	Users []string `json:"users"`
+	Mods  bool     `json:"mods"`
+	VIPs  bool     `json:"vips"`
+	// MinSubTier protects the subscribers of this tier and higher, zero disables it
+	MinSubTier int `json:"minSubTier"`
+	// FollowedFor protects the viewers following the channel for at least this long, zero disables it
+	FollowedFor time.Duration `json:"followedFor"`
+}
+
+func DefaultProtectionSettings() *ProtectionSettings {
+	return &ProtectionSettings{
+		Users:       []string{},
+		Mods:        true,
+		VIPs:        false,
+		MinSubTier:  0,
+		FollowedFor: 0,
+	}
+}
+
+// BudgetSettings caps the timeouts handed out during a stream, the timeouts above the caps are only announced.
+// Zero disables a cap.
+type BudgetSettings struct {
+	Enabled bool `json:"enabled"`
+	// StreamTimeout is the total timeout time of all viewers per stream
+	StreamTimeout time.Duration `json:"streamTimeout"`
+	// UserTimeout is the total timeout time of a single viewer per stream
+	UserTimeout time.Duration `json:"userTimeout"`
+	// SessionUsers is the number of distinct viewers timed out per killer session
+	SessionUsers int `json:"sessionUsers"`
+	// RepeatFactor scales every further timeout of the same viewer in a stream, e.g. 0.5 halves each one
+	RepeatFactor float64 `json:"repeatFactor"`
+}
+
+func DefaultBudgetSettings() *BudgetSettings {
+	return &BudgetSettings{
+		Enabled:       true,
+		StreamTimeout: 2 * time.Hour,
+		UserTimeout:   20 * time.Minute,
+		SessionUsers:  10,
+		RepeatFactor:  0.5,
+	}
+}
+
+func DefaultAllowedCategories() []string {
+	return []string{"Dead by Daylight"}
+}
+
+type DifficultyCurve struct {
+	Field    string  `json:"field"`
+	Exponent float64 `json:"exponent"`
+}
+
+// DifficultySettings scales killer settings by pressure^exponent of each curve,
+// where pressure 1 means a chat of ReferenceViewers viewers writing ReferenceMessagesPerMinute messages.
+type DifficultySettings struct {
+	Enabled                    bool              `json:"enabled"`
+	ReferenceViewers           int               `json:"referenceViewers"`
+	ReferenceMessagesPerMinute float64           `json:"referenceMessagesPerMinute"`
+	ViewerWeight               float64           `json:"viewerWeight"`
+	MinPressure                float64           `json:"minPressure"`
+	MaxPressure                float64           `json:"maxPressure"`
+	Curves                     []DifficultyCurve `json:"curves"`
+}
+
+func DefaultDifficultySettings() *DifficultySettings {
+	return &DifficultySettings{
+		Enabled:                    false,
+		ReferenceViewers:           100,
+		ReferenceMessagesPerMinute: 20,
+		ViewerWeight:               0.5,
+		MinPressure:                0.25,
+		MaxPressure:                4,
+		Curves: []DifficultyCurve{
+			{Field: "reactChance", Exponent: 0.3},
+			{Field: "hitChance", Exponent: 0.1},
+			{Field: "fatalHit", Exponent: 0.5},
+			{Field: "frenzyTimeout", Exponent: 0.3},
+			{Field: "timeout", Exponent: 0.3},
+			{Field: "palletStunChance", Exponent: -0.5},
+			{Field: "lockerStunChance", Exponent: -0.5},
+			{Field: "bodyBlockSuccessChance", Exponent: -0.5},
+			{Field: "revealChance", Exponent: -0.5},
+		},
 	}
 }
 
+type AddOnsSettings struct {
+	Enabled bool     `json:"enabled"`
+	Banned  []string `json:"banned"`
+}
+
+func DefaultAddOnsSettings() *AddOnsSettings {
+	return &AddOnsSettings{
+		Enabled: true,
+		Banned:  []string{},
+	}
+}
+
+type CommandsSettings struct {
+	Disabled []string        `json:"disabled"`
+	Custom   []CustomCommand `json:"custom"`
+}
+
+func DefaultCommandsSettings() *CommandsSettings {
+	return &CommandsSettings{
+		Disabled: []string{},
+		Custom:   []CustomCommand{},
+	}
+}
+
+type CustomCommand struct {
+	Name           string        `json:"name"`
+	Aliases        []string      `json:"aliases"`
+	Response       string        `json:"response"`
+	Permission     string        `json:"permission"`
+	GlobalCooldown time.Duration `json:"globalCooldown"`
+	UserCooldown   time.Duration `json:"userCooldown"`
+	Override       bool          `json:"override"`
+}
+
 type GeneralKillerSettings struct {
 	DelayBetweenKillers   time.Duration `json:"delayBetweenKillers"`
 	DelayAtTheStreamStart time.Duration `json:"delayAtTheStreamStart"`
 	MinNumberOfViewers    int           `json:"minNumberOfViewers"`
+	AllowedCategories     []string      `json:"allowedCategories"`
 }
 
 func DefaultGeneralKillerSettings() *GeneralKillerSettings {
@@ -63,6 +332,26 @@ func DefaultGeneralKillerSettings() *GeneralKillerSettings {
 		DelayBetweenKillers:   2 * time.Hour,
 		DelayAtTheStreamStart: 30 * time.Minute,
 		MinNumberOfViewers:    10,
+		AllowedCategories:     DefaultAllowedCategories(),
+	}
+}
+
+type ScheduleWindow struct {
+	Cron     string        `json:"cron"`
+	Duration time.Duration `json:"duration"`
+}
+
+type SchedulerSettings struct {
+	Windows    []ScheduleWindow `json:"windows"`
+	AntiRepeat bool             `json:"antiRepeat"`
+	PityFactor float64          `json:"pityFactor"`
+}
+
+func DefaultSchedulerSettings() *SchedulerSettings {
+	return &SchedulerSettings{
+		Windows:    []ScheduleWindow{},
+		AntiRepeat: true,
+		PityFactor: 0.5,
 	}
 }
 
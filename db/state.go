@@ -1,11 +1,13 @@
 package db
 
 type LegionState struct {
-	HitCount int `json:"hitCount"`
+	HitCount int             `json:"hitCount"`
+	Marked   map[string]bool `json:"marked"`
 }
 
 type GhostFaceState struct {
 	StalkedThisRound map[string]bool `json:"stalkedThisRound"`
+	RevealAttempts   map[string]int  `json:"revealAttempts"`
 }
 
 type PinheadState struct {
@@ -15,3 +17,11 @@ type PinheadState struct {
 type DredgeState struct {
 	Votes map[string]string `json:"votes"`
 }
+
+type CustomKillerState struct {
+	Name     string `json:"name"`
+	Hits     int    `json:"hits"`
+	Hooks    int    `json:"hooks"`
+	Timeouts int    `json:"timeouts"`
+	Messages int    `json:"messages"`
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type SettingModifier struct {
+	Source   string   `json:"source"`
+	Field    string   `json:"field"`
+	Multiply float64  `json:"multiply,omitempty"`
+	Add      float64  `json:"add,omitempty"`
+	Set      *float64 `json:"set,omitempty"`
+}
+
+// EffectiveKillers returns the killer settings with the current session's modifiers applied.
+// The stored settings are never modified.
+func (s ChannelState) EffectiveKillers() KillersSettings {
+	return ApplyModifiers(s.Settings.Killers, s.Session.Killer, s.Session.Modifiers)
+}
+
+// ApplyModifiers applies modifiers to a copy of the settings of the given killer,
+// fields are matched by their json names and modifiers for unknown fields are ignored.
+func ApplyModifiers(killers KillersSettings, killerName string, modifiers []SettingModifier) KillersSettings {
+	if killerName == "" || len(modifiers) == 0 {
+		return killers
+	}
+
+	killersValue := reflect.ValueOf(&killers).Elem()
+
+	for i := 0; i < killersValue.NumField(); i++ {
+		if jsonName(killersValue.Type().Field(i)) != killerName {
+			continue
+		}
+
+		settingsPtr := killersValue.Field(i)
+		if settingsPtr.Kind() != reflect.Pointer || settingsPtr.IsNil() {
+			return killers
+		}
+
+		settingsCopy := reflect.New(settingsPtr.Elem().Type())
+		settingsCopy.Elem().Set(settingsPtr.Elem())
+
+		for _, modifier := range modifiers {
+			applyModifier(settingsCopy.Elem(), modifier)
+		}
+
+		settingsPtr.Set(settingsCopy)
+		break
+	}
+
+	return killers
+}
+
+func applyModifier(settings reflect.Value, modifier SettingModifier) {
+	for i := 0; i < settings.NumField(); i++ {
+		if jsonName(settings.Type().Field(i)) != modifier.Field {
+			continue
+		}
+
+		field := settings.Field(i)
+
+		switch {
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			field.SetInt(int64(modifier.apply(float64(field.Int()))))
+		case field.Kind() == reflect.Int:
+			field.SetInt(max(1, int64(math.Round(modifier.apply(float64(field.Int()))))))
+		case field.Kind() == reflect.Float64:
+			value := modifier.apply(field.Float())
+			if strings.HasSuffix(modifier.Field, "Chance") {
+				value = min(max(value, 0), 1)
+			}
+			field.SetFloat(value)
+		case field.Kind() == reflect.Bool:
+			field.SetBool(modifier.apply(boolToFloat(field.Bool())) > 0)
+		}
+
+		return
+	}
+}
+
+func (m SettingModifier) apply(value float64) float64 {
+	if m.Set != nil {
+		return *m.Set
+	}
+	if m.Multiply != 0 {
+		value *= m.Multiply
+	}
+	return value + m.Add
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return name
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// ByName returns the settings of the killer with the given name, or nil if there is none.
+func (k KillersSettings) ByName(killerName string) any {
+	killersValue := reflect.ValueOf(k)
+
+	for i := 0; i < killersValue.NumField(); i++ {
+		if jsonName(killersValue.Type().Field(i)) == killerName {
+			return killersValue.Field(i).Interface()
+		}
+	}
+
+	return nil
+}
+
+// HasField reports whether the settings of the given killer have a field with the given json name.
+func (k KillersSettings) HasField(killerName, field string) bool {
+	settings := reflect.ValueOf(k.ByName(killerName))
+	if settings.Kind() != reflect.Pointer || settings.IsNil() {
+		return false
+	}
+
+	settingsType := settings.Elem().Type()
+	for i := 0; i < settingsType.NumField(); i++ {
+		if jsonName(settingsType.Field(i)) == field {
+			return true
+		}
+	}
+
+	return false
+}
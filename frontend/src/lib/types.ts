@@ -7,11 +7,32 @@ export interface User {
 export interface Settings {
   disabled: boolean;
   language: string;
+  safeMode: boolean;
   killers: KillersSettings;
   chat: ChatSettings;
   steam: SteamSettings;
 }
 
+export interface SafeModeAction {
+  time: string;
+  type: 'timeout' | 'delete' | 'unban' | 'emote_on' | 'emote_off';
+  username?: string;
+  duration?: number;
+  messageId?: string;
+}
+
+export interface Punishment {
+  id: number;
+  time: string;
+  kind: 'timeout' | 'unban' | 'delete' | 'emote_on' | 'emote_off';
+  username?: string;
+  messageId?: string;
+  duration?: number;
+  reason?: string;
+  revertOf?: number;
+  outstanding: boolean;
+}
+
 export interface SteamSettings {
   steamId64: string;
   notifyNewComments: boolean;
@@ -22,6 +43,7 @@ export interface ChatSettings {
   startKillerOnRaid: boolean;
   followRaids: boolean;
   followRaidsMessage: string;
+  plainReplies: boolean;
 }
 
 export interface KillersSettings {
@@ -96,4 +118,15 @@ export interface ChannelStatus {
   title: string;
   subtitle: string;
   timeRemaining: number;
+  budget?: BudgetStatus;
+  warnings?: string[];
+}
+
+export interface BudgetStatus {
+  spent: number;
+  streamTimeout: number;
+  users: number;
+  sessionUsers: number;
+  sessionUsersLimit: number;
+  skipped: number;
 }
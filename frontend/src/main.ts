@@ -105,6 +105,27 @@ const i18n = createI18n({
         "raids": "🚀 Raids",
         "follow_raids": "Follow Outgoing Raids",
         "follow_raids_message": "Message To Send",
+        "replies": "💬 Replies",
+        "plain_replies": "Plain Replies",
+        "plain_replies_info": "Command responses are sent as regular messages instead of replies to the command",
+        "safe_mode": "Safe Mode",
+        "safe_mode_info": "The killers only announce their timeouts, message deletions, unbans and emote-only mode instead of applying them. Useful to try the bot out on your channel",
+        "safe_mode_log": "🛡️ Safe Mode Actions",
+        "safe_mode_timeout": "Would have timed out {username} for {duration}",
+        "safe_mode_delete": "Would have deleted a message",
+        "safe_mode_unban": "Would have unbanned {username}",
+        "safe_mode_emote_on": "Would have turned on emote-only mode",
+        "safe_mode_emote_off": "Would have turned off emote-only mode",
+        "budget_status": "Timeouts this stream: {spent} of {limit} for {users} viewers, {skipped} skipped",
+        "punishments_title": "⚖️ Moderation Actions",
+        "punishment_timeout": "Timed out {username} for {duration}",
+        "punishment_unban": "Unbanned {username}",
+        "punishment_delete": "Deleted a message",
+        "punishment_emote_on": "Turned on emote-only mode",
+        "punishment_emote_off": "Turned off emote-only mode",
+        "revert": "Revert",
+        "reverted": "Action reverted",
+        "revert_failed": "Failed to revert action",
         "dredge": "🌙 The Dredge",
         "dredge_description": "Activates the Realm of Darkness (emote-only mode) for the entire duration. Users can vote on who to hang by sending the victim's username to the bot via DM. If the vote has a clear winner, that user will be killed and hooked at the end of the Realm of Darkness. Otherwise, The Dredge simply leaves.",
         "misc_title": "Misc",
@@ -216,6 +237,27 @@ const i18n = createI18n({
         "raids": "🚀 Рейды",
         "follow_raids": "Переходить по исходящим рейдам",
         "follow_raids_message": "Какую фразу писать",
+        "replies": "💬 Ответы",
+        "plain_replies": "Обычные Ответы",
+        "plain_replies_info": "Ответы на команды отправляются обычными сообщениями, а не ответом на команду",
+        "safe_mode": "Безопасный Режим",
+        "safe_mode_info": "Убийцы только объявляют таймауты, удаление сообщений, разбаны и режим только эмоутов, но не применяют их. Удобно, чтобы опробовать бота на своём канале",
+        "safe_mode_log": "🛡️ Действия Безопасного Режима",
+        "safe_mode_timeout": "{username} получил бы таймаут на {duration}",
+        "safe_mode_delete": "Сообщение было бы удалено",
+        "safe_mode_unban": "{username} был бы разбанен",
+        "safe_mode_emote_on": "Был бы включён режим только эмоутов",
+        "safe_mode_emote_off": "Был бы выключен режим только эмоутов",
+        "budget_status": "Таймауты за стрим: {spent} из {limit} на {users} зрителей, пропущено {skipped}",
+        "punishments_title": "⚖️ Действия Модерации",
+        "punishment_timeout": "Таймаут {username} на {duration}",
+        "punishment_unban": "Разбан {username}",
+        "punishment_delete": "Удалено сообщение",
+        "punishment_emote_on": "Включён режим только эмоутов",
+        "punishment_emote_off": "Выключен режим только эмоутов",
+        "revert": "Отменить",
+        "reverted": "Действие отменено",
+        "revert_failed": "Не удалось отменить действие",
         "dredge": "🌙 Грязь",
         "dredge_description": "Включает Царство Мрака (режим только для эмоутов) на все время действия. Пользователи могут голосовать кого повесить, отправляя юзернейм жертвы боту в лс. Если голование имеет явного победителя, в конце Царства Мрака этот пользователь будет убит и повешен на крюк. Иначе, Грязь просто уходит.",
         "misc_title": "Прочее",
@@ -4,6 +4,7 @@ go 1.24
 
 require (
 	github.com/PuerkitoBio/goquery v1.10.3
+	github.com/alitto/pond/v2 v2.3.4
 	github.com/elliotchance/pie/v2 v2.9.1
 	github.com/gempir/go-twitch-irc/v4 v4.2.0
 	github.com/go-playground/assert/v2 v2.2.0
@@ -28,7 +29,6 @@ require (
 )
 
 require (
-	github.com/alitto/pond/v2 v2.3.4 // indirect
 	github.com/andybalholm/cascadia v1.3.3 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.9 // indirect
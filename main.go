@@ -9,13 +9,29 @@ import (
 	slogtelegram "github.com/samber/slog-telegram/v2"
 	"legion-bot-v2/api"
 	"legion-bot-v2/bot"
+	"legion-bot-v2/bot/achievements"
+	"legion-bot-v2/bot/addons"
+	"legion-bot-v2/bot/bloodpoints"
+	"legion-bot-v2/bot/budget"
+	"legion-bot-v2/bot/commands"
+	"legion-bot-v2/bot/events"
+	"legion-bot-v2/bot/greetings"
+	"legion-bot-v2/bot/highlights"
 	"legion-bot-v2/bot/i18n"
 	"legion-bot-v2/bot/killer"
+	"legion-bot-v2/bot/killer/custom"
 	"legion-bot-v2/bot/killer/doctor"
 	"legion-bot-v2/bot/killer/dredge"
 	"legion-bot-v2/bot/killer/ghostface"
 	"legion-bot-v2/bot/killer/legion"
 	"legion-bot-v2/bot/killer/pinhead"
+	"legion-bot-v2/bot/permissions"
+	"legion-bot-v2/bot/protection"
+	"legion-bot-v2/bot/punishments"
+	"legion-bot-v2/bot/rituals"
+	"legion-bot-v2/bot/safemode"
+	"legion-bot-v2/bot/scheduler"
+	"legion-bot-v2/bot/seasons"
 	"legion-bot-v2/cheatdetect"
 	"legion-bot-v2/config"
 	"legion-bot-v2/db"
@@ -31,16 +47,14 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 )
 
 // TODO:
 // display problems
 // add more Info icons in the settings
-// greetings
-// !clip
 // improve chatbot ai
 // documentation
-// killer specific help page (!killer) + generic (!legionbot) help page
 
 // TODO: potential bugs
 // check if messages are being processed sequentially
@@ -51,10 +65,8 @@ import (
 
 // TODO: these require the stream monitoring:
 // rewind the whole stream
-// chase clip with autoupload
 // scoreboard autosave
 // other streamers notification
-// match timestamps
 // decisive notification
 // flashlight blind
 // notes about streamers?
@@ -133,8 +145,6 @@ func main() {
 	}
 	defer chatActions.Shutdown()
 
-	do.ProvideValue(di, chatActions)
-
 	database, err := db.NewDatabase("data/database.db")
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
@@ -146,28 +156,80 @@ func main() {
 	timerManager := timers.NewManager()
 	do.ProvideValue(di, timerManager)
 
-	localiser, err := i18n.NewLocaliser()
+	localiser, err := i18n.NewLocaliser(di)
 	if err != nil {
 		log.Fatalf("Failed to initialize i18n: %v", err)
 	}
 	do.ProvideValue(di, localiser)
 
+	// timeouts pass the protection policy first, then the moderation budget and the safe mode of the channel
+	// the ledger only records the actions that really reach the chat
+	ledger := punishments.New(chatActions, database)
+	do.ProvideValue[punishments.Tracker](di, ledger)
+
+	moderation := budget.New(safemode.New(ledger, database, localiser), database, localiser)
+	do.ProvideValue[chat.Actions](di, protection.NewGuard(moderation, database))
+
 	gptInstance := gpt.NewYandexGpt(cfg)
 	do.ProvideValue(di, gptInstance)
 
+	eventBus := events.NewDispatcher()
+	do.ProvideValue(di, eventBus)
+
+	commandRegistry := commands.New(di)
+	do.ProvideValue(di, commandRegistry)
+
+	bloodpointsBank := bloodpoints.New(di)
+	do.ProvideValue(di, bloodpointsBank)
+
+	achievementEngine, err := achievements.New(di)
+	if err != nil {
+		log.Fatalf("Failed to initialize achievements: %v", err)
+	}
+	do.ProvideValue(di, achievementEngine)
+
+	ritualEngine, err := rituals.New(di)
+	if err != nil {
+		log.Fatalf("Failed to initialize rituals: %v", err)
+	}
+	do.ProvideValue(di, ritualEngine)
+
+	greeter := greetings.New(di)
+	do.ProvideValue(di, greeter)
+
+	highlightsEngine := highlights.New(di)
+	do.ProvideValue(di, highlightsEngine)
+
+	permissionChecker := permissions.New(di)
+	do.ProvideValue(di, permissionChecker)
+
+	addOnCatalog, err := addons.New(di)
+	if err != nil {
+		log.Fatalf("Failed to initialize add-ons: %v", err)
+	}
+	do.ProvideValue(di, addOnCatalog)
+
 	killerMap := map[string]killer.Killer{
 		"legion":    legion.New(di),
 		"ghostface": ghostface.New(di),
 		"doctor":    doctor.New(di),
 		"pinhead":   pinhead.New(di),
 		"dredge":    dredge.New(di),
+		"custom":    custom.New(di),
 	}
 	do.ProvideValue(di, killerMap)
 
+	killerScheduler := scheduler.New(di)
+	do.ProvideValue(di, killerScheduler)
+
 	botInstance := bot.NewBot(di)
 	botInstance.Init()
 	do.ProvideValue(di, botInstance)
 
+	seasonManager := seasons.New(di)
+	go seasonManager.Run(context.Background())
+	do.ProvideValue(di, seasonManager)
+
 	chatProducer := producer.NewTwitchProducer(di)
 	defer chatProducer.Shutdown()
 	do.ProvideValue(di, chatProducer)
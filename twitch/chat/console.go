@@ -26,6 +26,29 @@ func (a *ConsoleActions) SetEmoteMode(channel string, enabled bool) {
 	)
 }
 
+func (a *ConsoleActions) CreateStreamMarker(channel, description string) {
+	slog.Debug("Create stream marker",
+		slog.String("channel", channel),
+		slog.String("description", description),
+	)
+}
+
+func (a *ConsoleActions) CreateClip(channel string) string {
+	slog.Debug("Create clip",
+		slog.String("channel", channel),
+	)
+
+	return "https://clips.twitch.tv/" + channel
+}
+
+func (a *ConsoleActions) IsBotModerator(channel string) (bool, error) {
+	return true, nil
+}
+
+func (a *ConsoleActions) GetTokenScopes() ([]string, error) {
+	return []string{ScopeBannedUsers, ScopeChatMessages, ScopeChatSettings}, nil
+}
+
 func (a *ConsoleActions) GetViewerList(channel string) []string {
 	return []string{util.BotUsername}
 }
@@ -45,6 +68,23 @@ func (a *ConsoleActions) GetViewerCount(channel string) int {
 	return 15
 }
 
+func (a *ConsoleActions) GetCategory(channel string) (string, error) {
+	slog.Debug("Getting channel category",
+		slog.String("channel", channel),
+	)
+
+	return "Dead by Daylight", nil
+}
+
+func (a *ConsoleActions) GetFollowedAt(channel, username string) time.Time {
+	slog.Debug("Getting follow date",
+		slog.String("channel", channel),
+		slog.String("username", username),
+	)
+
+	return time.Now().Add(-30 * 24 * time.Hour)
+}
+
 func (a *ConsoleActions) GetStartTime(channel string) time.Time {
 	slog.Debug("Getting channel stream start time",
 		slog.String("channel", channel),
@@ -54,17 +94,36 @@ func (a *ConsoleActions) GetStartTime(channel string) time.Time {
 }
 
 func (a *ConsoleActions) SendMessage(channel, text string) {
-	slog.Debug("<<<",
-		slog.String("channel", channel),
-		slog.String("text", text),
-	)
+	a.SendMessageWithPriority(channel, text, PriorityNormal)
+}
+
+func (a *ConsoleActions) SendMessageWithPriority(channel, text string, priority Priority) {
+	for _, part := range SplitMessage(text) {
+		slog.Debug("<<<",
+			slog.String("channel", channel),
+			slog.String("text", part),
+			slog.Int("priority", int(priority)),
+		)
+	}
+}
+
+func (a *ConsoleActions) ReplyMessage(channel, parentID, text string) {
+	for _, part := range SplitMessage(text) {
+		slog.Debug("<<<",
+			slog.String("channel", channel),
+			slog.String("reply_to", parentID),
+			slog.String("text", part),
+		)
+	}
 }
 
 func (a *ConsoleActions) SendForeignMessage(channel, text string) {
-	slog.Debug("Send foreign message",
-		slog.String("channel", channel),
-		slog.String("text", text),
-	)
+	for _, part := range SplitMessage(text) {
+		slog.Debug("Send foreign message",
+			slog.String("channel", channel),
+			slog.String("text", part),
+		)
+	}
 }
 
 func (a *ConsoleActions) TimeoutUser(channel, username string, duration time.Duration, reason string) {
@@ -4,16 +4,36 @@ import (
 	"time"
 )
 
+// Token scopes required by the moderation actions
+const (
+	ScopeBannedUsers  = "moderator:manage:banned_users"
+	ScopeChatMessages = "moderator:manage:chat_messages"
+	ScopeChatSettings = "moderator:manage:chat_settings"
+)
+
 type Actions interface {
 	GetUserIDByUsername(username string) string
 	DeleteMessage(channel, id string)
 	SendMessage(channel, text string)
+	SendMessageWithPriority(channel, text string, priority Priority)
+	// ReplyMessage sends a message as a reply to the message with parentID, an empty parentID sends a plain message
+	ReplyMessage(channel, parentID, text string)
 	SendForeignMessage(channel, text string)
 	TimeoutUser(channel, username string, duration time.Duration, reason string)
 	GetStartTime(channel string) time.Time
 	GetViewerCount(channel string) int
+	GetCategory(channel string) (string, error)
+	// GetFollowedAt returns when the user followed the channel, or zero if they don't follow it
+	GetFollowedAt(channel, username string) time.Time
 	UnbanUser(channel, username string)
 	GetViewerList(channel string) []string
 	SetEmoteMode(channel string, enabled bool)
+	CreateStreamMarker(channel, description string)
+	// CreateClip clips the last seconds of the stream and returns the link to the clip, or an empty string on failure
+	CreateClip(channel string) string
+	// IsBotModerator reports whether the bot account is a moderator or the broadcaster of the channel
+	IsBotModerator(channel string) (bool, error)
+	// GetTokenScopes returns the scopes granted to the token of the bot account
+	GetTokenScopes() ([]string, error)
 	Shutdown()
 }
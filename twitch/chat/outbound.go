@@ -0,0 +1,287 @@
+package chat
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type Priority int
+
+const (
+	// PriorityLow is used for flavour lines that may be dropped when the bot is close to the rate limit
+	PriorityLow Priority = iota
+	PriorityNormal
+)
+
+// Twitch limits are shared by all channels of the bot account,
+// the limit of a channel depends on whether the bot is a moderator there.
+const (
+	MaxMessageLength = 500
+
+	rateWindow      = 30 * time.Second
+	userRateLimit   = 20
+	modRateLimit    = 100
+	channelInterval = time.Second
+
+	// pressureRatio is the share of the window after which low priority lines are dropped
+	pressureRatio = 0.75
+	// maxLowPriorityAge drops low priority lines that waited so long they are no longer relevant
+	maxLowPriorityAge = 10 * time.Second
+	maxPendingPerChan = 30
+)
+
+type outgoing struct {
+	text     string
+	replyTo  string
+	priority Priority
+	queued   time.Time
+	// foreign lines go to channels the bot doesn't play in, they are sent through the API instead of IRC
+	foreign bool
+}
+
+// Outbound is the global scheduler of outgoing chat messages.
+// Pending messages to the same channel are sent as a single line when they fit,
+// replies are always sent on their own so that they stay attached to the parent message.
+type Outbound struct {
+	send func(channel string, msg outgoing)
+
+	mutex    sync.Mutex
+	pending  map[string][]outgoing
+	channels []string
+	sent     []time.Time
+	lastSent map[string]time.Time
+	mods     map[string]bool
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func NewOutbound(send func(channel string, msg outgoing)) *Outbound {
+	o := &Outbound{
+		send:     send,
+		pending:  make(map[string][]outgoing),
+		lastSent: make(map[string]time.Time),
+		mods:     make(map[string]bool),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	go o.run()
+
+	return o
+}
+
+// SetModerator records whether the bot is a moderator in the channel, which raises its rate limit.
+func (o *Outbound) SetModerator(channel string, isMod bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.mods[channel] != isMod {
+		slog.Info("Bot moderator status changed",
+			slog.String("channel", channel),
+			slog.Bool("mod", isMod),
+		)
+	}
+
+	o.mods[channel] = isMod
+}
+
+// Enqueue schedules a line, replyTo is the ID of the message it replies to or empty for a plain message.
+func (o *Outbound) Enqueue(channel, replyTo, text string, priority Priority) {
+	o.enqueue(channel, outgoing{
+		text:     text,
+		replyTo:  replyTo,
+		priority: priority,
+	})
+}
+
+// EnqueueForeign schedules a line to a channel the bot doesn't play in, e.g. a raid follow-up.
+// It counts against the same account limit as the rest of the lines.
+func (o *Outbound) EnqueueForeign(channel, text string) {
+	o.enqueue(channel, outgoing{
+		text:     text,
+		priority: PriorityNormal,
+		foreign:  true,
+	})
+}
+
+func (o *Outbound) enqueue(channel string, msg outgoing) {
+	msg.queued = time.Now()
+
+	o.mutex.Lock()
+
+	queue := o.pending[channel]
+	if len(queue) >= maxPendingPerChan {
+		// make room by dropping the oldest low priority line, otherwise the new line is lost
+		i := slices.IndexFunc(queue, func(m outgoing) bool { return m.priority == PriorityLow })
+		if i < 0 || msg.priority == PriorityLow {
+			o.mutex.Unlock()
+			slog.Warn("Outgoing queue is full, dropping message",
+				slog.String("channel", channel),
+				slog.String("text", msg.text),
+			)
+			return
+		}
+		queue = slices.Delete(queue, i, i+1)
+	}
+
+	if len(queue) == 0 && !slices.Contains(o.channels, channel) {
+		o.channels = append(o.channels, channel)
+	}
+
+	o.pending[channel] = append(queue, msg)
+
+	o.mutex.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (o *Outbound) Shutdown() {
+	o.once.Do(func() {
+		close(o.done)
+	})
+}
+
+func (o *Outbound) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		wait := o.flush(time.Now())
+
+		timer.Reset(wait)
+
+		select {
+		case <-o.done:
+			return
+		case <-o.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// flush sends everything that is allowed right now and returns how long to wait before the next attempt.
+func (o *Outbound) flush(now time.Time) time.Duration {
+	o.mutex.Lock()
+
+	o.pruneWindow(now)
+
+	type sending struct {
+		channel string
+		msg     outgoing
+	}
+
+	wait := time.Hour
+	var batch []sending
+
+	// channels are served round-robin, so that a single busy channel can't starve the others
+	for _, channel := range slices.Clone(o.channels) {
+		limit := o.limit(channel)
+
+		if len(o.sent) >= limit {
+			wait = min(wait, o.sent[0].Add(rateWindow).Sub(now))
+			continue
+		}
+
+		if !o.mods[channel] {
+			if next := o.lastSent[channel].Add(channelInterval); now.Before(next) {
+				wait = min(wait, next.Sub(now))
+				continue
+			}
+		}
+
+		msg, ok := o.take(channel, now, limit)
+		if !ok {
+			continue
+		}
+
+		o.sent = append(o.sent, now)
+		o.lastSent[channel] = now
+		batch = append(batch, sending{channel: channel, msg: msg})
+
+		if len(o.pending[channel]) > 0 {
+			o.channels = append(slices.DeleteFunc(o.channels, func(c string) bool { return c == channel }), channel)
+			wait = min(wait, channelInterval)
+		}
+	}
+
+	o.mutex.Unlock()
+
+	for _, item := range batch {
+		o.send(item.channel, item.msg)
+	}
+
+	return max(wait, 10*time.Millisecond)
+}
+
+// take removes the next line of a channel, merging the following pending lines into it while they fit.
+func (o *Outbound) take(channel string, now time.Time, limit int) (outgoing, bool) {
+	underPressure := float64(len(o.sent)) >= float64(limit)*pressureRatio
+
+	queue := slices.DeleteFunc(o.pending[channel], func(m outgoing) bool {
+		drop := m.priority == PriorityLow && (underPressure || now.Sub(m.queued) > maxLowPriorityAge)
+		if drop {
+			slog.Debug("Dropping low priority message",
+				slog.String("channel", channel),
+				slog.String("text", m.text),
+			)
+		}
+		return drop
+	})
+
+	if len(queue) == 0 {
+		o.removeChannel(channel)
+		return outgoing{}, false
+	}
+
+	msg := queue[0]
+	taken := 1
+
+	for _, m := range queue[1:] {
+		if msg.replyTo != "" || m.replyTo != "" || msg.foreign != m.foreign {
+			break
+		}
+		if utf8.RuneCountInString(msg.text)+1+utf8.RuneCountInString(m.text) > MaxMessageLength {
+			break
+		}
+
+		msg.text += " " + m.text
+		taken++
+	}
+
+	queue = queue[taken:]
+	if len(queue) == 0 {
+		o.removeChannel(channel)
+	} else {
+		o.pending[channel] = queue
+	}
+
+	return msg, true
+}
+
+func (o *Outbound) removeChannel(channel string) {
+	delete(o.pending, channel)
+	o.channels = slices.DeleteFunc(o.channels, func(c string) bool { return c == channel })
+}
+
+func (o *Outbound) limit(channel string) int {
+	if o.mods[channel] {
+		return modRateLimit
+	}
+	return userRateLimit
+}
+
+func (o *Outbound) pruneWindow(now time.Time) {
+	i := 0
+	for i < len(o.sent) && now.Sub(o.sent[i]) >= rateWindow {
+		i++
+	}
+	o.sent = o.sent[i:]
+}
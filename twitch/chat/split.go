@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageParts caps how many chat messages a single long line may turn into
+const MaxMessageParts = 4
+
+// partSuffixLength is the room reserved for the " (1/4)" numbering of the parts
+const partSuffixLength = len(" (0/0)")
+
+// SplitMessage breaks a line longer than the Twitch limit into parts at word boundaries.
+// Twitch counts characters as runes, so Cyrillic letters and emoji count as one each.
+// A leading @mention is repeated on every part, parts are numbered when there are more than two
+// and whatever doesn't fit into MaxMessageParts is cut off.
+func SplitMessage(text string) []string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= MaxMessageLength {
+		return []string{text}
+	}
+
+	var mention string
+	body := text
+	if strings.HasPrefix(text, "@") {
+		if first, rest, ok := strings.Cut(text, " "); ok {
+			mention = first + " "
+			body = rest
+		}
+	}
+
+	limit := MaxMessageLength - utf8.RuneCountInString(mention) - partSuffixLength
+	if limit <= 0 {
+		// a mention this long can't be repeated, split the text as is
+		mention = ""
+		body = text
+		limit = MaxMessageLength - partSuffixLength
+	}
+
+	parts := splitWords(body, limit)
+
+	if len(parts) > MaxMessageParts {
+		parts = parts[:MaxMessageParts]
+
+		last := []rune(parts[MaxMessageParts-1])
+		if len(last)+1 > limit {
+			last = last[:limit-1]
+		}
+		parts[MaxMessageParts-1] = string(last) + "…"
+	}
+
+	result := make([]string, 0, len(parts))
+	for i, part := range parts {
+		part = mention + part
+		if len(parts) > 2 {
+			part += fmt.Sprintf(" (%d/%d)", i+1, len(parts))
+		}
+		result = append(result, part)
+	}
+
+	return result
+}
+
+// splitWords packs the words of a text into parts of at most limit runes, words longer than that are cut.
+func splitWords(text string, limit int) []string {
+	var parts []string
+	var current string
+
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > limit {
+			if current != "" {
+				parts = append(parts, current)
+				current = ""
+			}
+
+			runes := []rune(word)
+			parts = append(parts, string(runes[:limit]))
+			word = string(runes[limit:])
+		}
+
+		switch {
+		case word == "":
+		case current == "":
+			current = word
+		case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > limit:
+			parts = append(parts, current)
+			current = word
+		default:
+			current += " " + word
+		}
+	}
+
+	if current != "" {
+		parts = append(parts, current)
+	}
+
+	return parts
+}
@@ -0,0 +1,53 @@
+package chat
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitShortMessage(t *testing.T) {
+	require.Equal(t, []string{"@user hello"}, SplitMessage("  @user hello "))
+}
+
+func TestSplitKeepsMention(t *testing.T) {
+	text := "@viewer " + strings.Repeat("слово ", 150)
+
+	parts := SplitMessage(text)
+	require.Len(t, parts, 2)
+
+	for _, part := range parts {
+		require.True(t, strings.HasPrefix(part, "@viewer "))
+		require.LessOrEqual(t, utf8.RuneCountInString(part), MaxMessageLength)
+		require.False(t, strings.HasSuffix(part, "/2)"))
+	}
+}
+
+func TestSplitNumbersParts(t *testing.T) {
+	text := strings.Repeat("🔪 legion ", 200)
+
+	parts := SplitMessage(text)
+	require.Len(t, parts, 4)
+
+	for i, part := range parts {
+		require.LessOrEqual(t, utf8.RuneCountInString(part), MaxMessageLength)
+		require.True(t, strings.HasSuffix(part, " ("+string(rune('1'+i))+"/4)"))
+	}
+}
+
+func TestSplitCapsParts(t *testing.T) {
+	text := strings.Repeat("word ", 1000)
+
+	parts := SplitMessage(text)
+	require.Len(t, parts, MaxMessageParts)
+	require.Contains(t, parts[MaxMessageParts-1], "… (4/4)")
+}
+
+func TestSplitLongWord(t *testing.T) {
+	text := strings.Repeat("a", 700)
+
+	parts := SplitMessage(text)
+	require.Len(t, parts, 2)
+	require.Equal(t, 700, utf8.RuneCountInString(parts[0]+parts[1]))
+}
@@ -1,6 +1,8 @@
 package chat
 
 import (
+	"fmt"
+	"github.com/gempir/go-twitch-irc/v4"
 	"github.com/jellydator/ttlcache/v3"
 	"github.com/nicklaw5/helix/v2"
 	"github.com/samber/do"
@@ -16,6 +18,9 @@ import (
 
 var _ Actions = (*TwitchActions)(nil)
 
+// maxMarkerDescription is the longest stream marker description accepted by twitch
+const maxMarkerDescription = 140
+
 type TwitchActions struct {
 	cfg         *config.Config
 	accessToken string
@@ -23,6 +28,7 @@ type TwitchActions struct {
 
 	queueMutex sync.Mutex
 	queues     map[string]*taskq.Queue
+	outbound   *Outbound
 
 	userIdCache *ttlcache.Cache[string, string] // username -> userId
 }
@@ -34,12 +40,43 @@ func NewTwitchActions(di *do.Injector) Actions {
 
 	go userIdCache.Start()
 
-	return &TwitchActions{
+	api := do.MustInvoke[*twitch_api.TwitchApi](di)
+
+	t := &TwitchActions{
 		cfg:         do.MustInvoke[*config.Config](di),
-		api:         do.MustInvoke[*twitch_api.TwitchApi](di),
+		api:         api,
 		queues:      make(map[string]*taskq.Queue),
 		userIdCache: userIdCache,
 	}
+
+	outbound := NewOutbound(func(channel string, msg outgoing) {
+		if msg.foreign {
+			t.sendForeignMessage(channel, msg.text)
+			return
+		}
+
+		slog.Info("<<<",
+			slog.String("channel", channel),
+			slog.String("reply_to", msg.replyTo),
+			slog.String("text", msg.text),
+		)
+
+		if msg.replyTo != "" {
+			api.IrcClient().Reply(channel, msg.replyTo, msg.text)
+			return
+		}
+
+		api.IrcClient().Say(channel, msg.text)
+	})
+	t.outbound = outbound
+
+	// twitch sends USERSTATE on join and after every message, its badges tell whether the bot is a moderator
+	api.IrcClient().OnUserStateMessage(func(message twitch.UserStateMessage) {
+		isMod := message.User.IsMod || message.User.Badges["moderator"] > 0 || message.User.Badges["broadcaster"] > 0
+		outbound.SetModerator(message.Channel, isMod)
+	})
+
+	return t
 }
 
 func (t *TwitchActions) getQueue(channel string) *taskq.Queue {
@@ -53,6 +90,8 @@ func (t *TwitchActions) getQueue(channel string) *taskq.Queue {
 }
 
 func (t *TwitchActions) Shutdown() {
+	t.outbound.Shutdown()
+
 	t.queueMutex.Lock()
 	defer t.queueMutex.Unlock()
 
@@ -129,6 +168,122 @@ func (t *TwitchActions) SetEmoteMode(channel string, enabled bool) {
 	})
 }
 
+func (t *TwitchActions) IsBotModerator(channel string) (bool, error) {
+	if channel == util.BotUsername {
+		return true, nil
+	}
+
+	params := &helix.GetModeratedChannelsParams{
+		UserID: util.BotUserID,
+		First:  100,
+	}
+
+	for {
+		res, err := t.api.UserClient().GetModeratedChannels(params)
+		if err != nil {
+			return false, fmt.Errorf("failed to get moderated channels: %w", err)
+		}
+		if res.StatusCode >= 400 {
+			return false, fmt.Errorf("failed to get moderated channels: %s (%s)", res.Error, res.ErrorMessage)
+		}
+
+		for _, moderated := range res.Data.ModeratedChannels {
+			if moderated.BroadcasterLogin == channel {
+				return true, nil
+			}
+		}
+
+		if res.Data.Pagination.Cursor == "" {
+			return false, nil
+		}
+		params.After = res.Data.Pagination.Cursor
+	}
+}
+
+func (t *TwitchActions) GetTokenScopes() ([]string, error) {
+	valid, res, err := t.api.UserClient().ValidateToken(t.api.GetUserToken())
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate token: %w", err)
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid token: %s (%s)", res.Error, res.ErrorMessage)
+	}
+
+	return res.Data.Scopes, nil
+}
+
+func (t *TwitchActions) CreateStreamMarker(channel, description string) {
+	t.getQueue(channel).Enqueue(func() {
+		slog.Info("Create stream marker",
+			slog.String("channel", channel),
+			slog.String("description", description),
+		)
+
+		channelUserID := t.GetUserIDByUsername(channel)
+		if channelUserID == "" {
+			return
+		}
+
+		if runes := []rune(description); len(runes) > maxMarkerDescription {
+			description = string(runes[:maxMarkerDescription])
+		}
+
+		markerResp, err := t.api.UserClient().CreateStreamMarker(&helix.CreateStreamMarkerParams{
+			UserID:      channelUserID,
+			Description: description,
+		})
+		if err != nil {
+			slog.Error("Error creating stream marker",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+			return
+		}
+
+		if markerResp.StatusCode >= 400 {
+			slog.Error("Create stream marker API error",
+				slog.String("channel", channel),
+				slog.String("error", markerResp.Error),
+				slog.String("errorMsg", markerResp.ErrorMessage),
+			)
+		}
+	})
+}
+
+func (t *TwitchActions) CreateClip(channel string) string {
+	return taskq.Compute(t.getQueue(channel), func() string {
+		slog.Info("Create clip",
+			slog.String("channel", channel),
+		)
+
+		channelUserID := t.GetUserIDByUsername(channel)
+		if channelUserID == "" {
+			return ""
+		}
+
+		clipResp, err := t.api.UserClient().CreateClip(&helix.CreateClipParams{
+			BroadcasterID: channelUserID,
+		})
+		if err != nil {
+			slog.Error("Error creating clip",
+				slog.String("channel", channel),
+				slog.Any("error", err),
+			)
+			return ""
+		}
+		if clipResp.StatusCode >= 400 || len(clipResp.Data.ClipEditURLs) == 0 {
+			slog.Error("Create clip API error",
+				slog.String("channel", channel),
+				slog.String("error", clipResp.Error),
+				slog.String("errorMsg", clipResp.ErrorMessage),
+			)
+			return ""
+		}
+
+		return "https://clips.twitch.tv/" + clipResp.Data.ClipEditURLs[0].ID
+	})
+}
+
 func (t *TwitchActions) GetViewerList(channel string) []string {
 	result, err := t.api.IrcClient().Userlist(channel)
 	if err != nil {
@@ -253,18 +408,109 @@ func (t *TwitchActions) GetViewerCount(channel string) int {
 	})
 }
 
-func (t *TwitchActions) SendMessage(channel, text string) {
-	t.getQueue(channel).Enqueue(func() {
-		slog.Info("<<<",
+func (t *TwitchActions) GetCategory(channel string) (string, error) {
+	return taskq.ComputeWithError(t.getQueue(channel), func() (string, error) {
+		slog.Debug("Getting channel category",
 			slog.String("channel", channel),
-			slog.String("text", text),
 		)
 
-		t.api.IrcClient().Say(channel, text)
+		channelUserID := t.GetUserIDByUsername(channel)
+		if channelUserID == "" {
+			return "", fmt.Errorf("failed to get user id of %s", channel)
+		}
+
+		res, err := t.api.UserClient().GetChannelInformation(&helix.GetChannelInformationParams{
+			BroadcasterIDs: []string{channelUserID},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to get channel info: %w", err)
+		}
+		if res.StatusCode >= 400 {
+			return "", fmt.Errorf("failed to get channel info: %s (%s)", res.Error, res.ErrorMessage)
+		}
+
+		for _, c := range res.Data.Channels {
+			if c.BroadcasterID == channelUserID {
+				return c.GameName, nil
+			}
+		}
+
+		return "", fmt.Errorf("channel info of %s not found", channel)
 	})
 }
 
+func (t *TwitchActions) GetFollowedAt(channel, username string) time.Time {
+	return taskq.Compute(t.getQueue(channel), func() time.Time {
+		slog.Debug("Getting follow date",
+			slog.String("channel", channel),
+			slog.String("username", username),
+		)
+
+		channelUserID := t.GetUserIDByUsername(channel)
+		if channelUserID == "" {
+			return time.Time{}
+		}
+
+		userID := t.GetUserIDByUsername(username)
+		if userID == "" {
+			return time.Time{}
+		}
+
+		res, err := t.api.UserClient().GetChannelFollows(&helix.GetChannelFollowsParams{
+			BroadcasterID: channelUserID,
+			UserID:        userID,
+		})
+		if err != nil {
+			slog.Error("Failed to get channel follows",
+				slog.String("channel", channel),
+				slog.String("username", username),
+				slog.Any("error", err),
+			)
+			return time.Time{}
+		}
+		if res.StatusCode >= 400 {
+			slog.Error("Failed to get channel follows",
+				slog.String("channel", channel),
+				slog.String("username", username),
+				slog.String("error", res.Error),
+				slog.String("errorMsg", res.ErrorMessage),
+			)
+			return time.Time{}
+		}
+
+		for _, follow := range res.Data.Channels {
+			if follow.UserID == userID {
+				return follow.Followed.Time
+			}
+		}
+
+		return time.Time{}
+	})
+}
+
+func (t *TwitchActions) SendMessage(channel, text string) {
+	t.SendMessageWithPriority(channel, text, PriorityNormal)
+}
+
+func (t *TwitchActions) SendMessageWithPriority(channel, text string, priority Priority) {
+	for _, part := range SplitMessage(text) {
+		t.outbound.Enqueue(channel, "", part, priority)
+	}
+}
+
+func (t *TwitchActions) ReplyMessage(channel, parentID, text string) {
+	for _, part := range SplitMessage(text) {
+		t.outbound.Enqueue(channel, parentID, part, PriorityNormal)
+	}
+}
+
 func (t *TwitchActions) SendForeignMessage(channel, text string) {
+	for _, part := range SplitMessage(text) {
+		t.outbound.EnqueueForeign(channel, part)
+	}
+}
+
+func (t *TwitchActions) sendForeignMessage(channel, text string) {
 	t.getQueue(channel).Enqueue(func() {
 		slog.Info("Send foreign message",
 			slog.String("channel", channel),
@@ -4,6 +4,7 @@ import (
 	"github.com/gempir/go-twitch-irc/v4"
 	"github.com/samber/do"
 	"legion-bot-v2/bot"
+	"legion-bot-v2/bot/punishments"
 	"legion-bot-v2/config"
 	"legion-bot-v2/db"
 	"legion-bot-v2/twitch/twitch_api"
@@ -66,6 +67,14 @@ func (p *TwitchProducer) Run() error {
 		modTagStr, _ := message.Tags["mod"]
 
 		isMod := modTagStr == "1"
+		isVIP := message.User.Badges["vip"] > 0
+		isSubscriber := message.User.Badges["subscriber"] > 0 || message.User.Badges["founder"] > 0
+
+		// the subscriber badge version is 2xxx for tier 2 and 3xxx for tier 3
+		var subTier int
+		if isSubscriber {
+			subTier = max(1, message.User.Badges["subscriber"]/1000)
+		}
 
 		slog.Debug("Message",
 			slog.String("channel", channel),
@@ -75,11 +84,14 @@ func (p *TwitchProducer) Run() error {
 		)
 
 		p.botInstance.HandleMessage(db.Message{
-			ID:       message.ID,
-			Channel:  channel,
-			Username: username,
-			IsMod:    isMod,
-			Text:     text,
+			ID:           message.ID,
+			Channel:      channel,
+			Username:     username,
+			IsMod:        isMod,
+			IsVIP:        isVIP,
+			IsSubscriber: isSubscriber,
+			SubTier:      subTier,
+			Text:         text,
 		})
 	})
 
@@ -182,4 +194,6 @@ func (p *TwitchProducer) RemoveChannel(channel string) {
 
 		p.timersInstance.StopChannelTimers(channel)
 	})
+
+	p.botInstance.Reconcile(channel, punishments.ReasonDisabled)
 }
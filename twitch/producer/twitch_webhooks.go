@@ -38,6 +38,9 @@ func (p *TwitchProducer) registerAllListeners(channel string) {
 	p.queue.Enqueue(func() {
 		p.addStreamEndListener(channel, broadcasterID)
 	})
+	p.queue.Enqueue(func() {
+		p.addChannelUpdateListener(channel, broadcasterID)
+	})
 }
 
 func (p *TwitchProducer) addStreamStartListener(channel, broadcasterID string) {
@@ -130,6 +133,51 @@ func (p *TwitchProducer) addStreamEndListener(channel, broadcasterID string) {
 	)
 }
 
+func (p *TwitchProducer) addChannelUpdateListener(channel, broadcasterID string) {
+	chanState := p.database.GetState(channel)
+	channelUpdateId := chanState.Subs.ChannelUpdate
+
+	if channelUpdateId != "" {
+		_, _ = p.api.AppClient().RemoveEventSubSubscription(channelUpdateId)
+	}
+
+	resp, err := p.api.AppClient().CreateEventSubSubscription(&helix.EventSubSubscription{
+		Type:    helix.EventSubTypeChannelUpdate,
+		Version: "2",
+		Condition: helix.EventSubCondition{
+			BroadcasterUserID: broadcasterID,
+		},
+		Transport: helix.EventSubTransport{
+			Method:   "webhook",
+			Callback: fmt.Sprintf("%s/api/webhook/channel/update", p.cfg.BaseURL),
+			Secret:   p.cfg.Twitch.WebHookSecret,
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to create event sub for channel update",
+			slog.String("channel", channel),
+			slog.Any("error", err),
+		)
+		return
+	}
+	if len(resp.Data.EventSubSubscriptions) == 0 {
+		slog.Error("Failed to create event sub for channel update",
+			slog.String("channel", channel),
+			slog.String("error", resp.Error),
+			slog.String("errorMsg", resp.ErrorMessage),
+		)
+		return
+	}
+
+	sub := resp.Data.EventSubSubscriptions[0]
+	p.database.UpdateState(channel, func(state *db.ChannelState) {
+		state.Subs.ChannelUpdate = sub.ID
+	})
+	slog.Debug("Successfully created event sub for channel update",
+		slog.String("channel", channel),
+	)
+}
+
 func (p *TwitchProducer) addOutgoingRaidsListener(channel, broadcasterID string) {
 	chanState := p.database.GetState(channel)
 	raidSubId := chanState.Subs.RaidID
@@ -205,9 +253,19 @@ func (p *TwitchProducer) removeAllListeners(channel string) {
 		)
 	}
 
+	if chanState.Subs.ChannelUpdate != "" {
+		p.queue.Enqueue(func() {
+			_, _ = p.api.AppClient().RemoveEventSubSubscription(chanState.Subs.ChannelUpdate)
+		})
+		slog.Debug("Removed event sub for channel update subscription",
+			slog.String("channel", channel),
+		)
+	}
+
 	p.database.UpdateState(channel, func(state *db.ChannelState) {
 		state.Subs.RaidID = ""
 		state.Subs.StreamStart = ""
 		state.Subs.StreamEnd = ""
+		state.Subs.ChannelUpdate = ""
 	})
 }
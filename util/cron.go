@@ -0,0 +1,17 @@
+package util
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"strings"
+	"time"
+)
+
+// ParseCron parses a standard cron spec, evaluating it in loc unless the spec sets its own timezone.
+func ParseCron(spec string, loc *time.Location) (cron.Schedule, error) {
+	if !strings.HasPrefix(spec, "CRON_TZ=") && !strings.HasPrefix(spec, "TZ=") {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", loc, spec)
+	}
+
+	return cron.ParseStandard(spec)
+}